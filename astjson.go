@@ -0,0 +1,592 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/roycefanproxy/yaglox/constant"
+)
+
+const ASTSchemaVersion = 2
+
+type astDocument struct {
+	Version    int               `json:"version"`
+	Statements []json.RawMessage `json:"statements"`
+}
+
+type astNode map[string]interface{}
+
+type astObject map[string]json.RawMessage
+
+type astDecodeError struct {
+	msg string
+}
+
+func (e *astDecodeError) Error() string {
+	return "invalid AST: " + e.msg
+}
+
+var tokenTypes = func() map[string]constant.TokenType {
+	types := map[string]constant.TokenType{}
+	for t := constant.LeftParen; t <= constant.EOF; t++ {
+		types[t.String()] = t
+	}
+	return types
+}()
+
+func MarshalProgram(statements []Stmt) ([]byte, error) {
+	encoder := astEncoder{}
+
+	nodes := make([]interface{}, 0, len(statements))
+	for _, stmt := range statements {
+		nodes = append(nodes, stmt.AcceptInterface(encoder))
+	}
+
+	return json.MarshalIndent(astNode{
+		"version":    ASTSchemaVersion,
+		"statements": nodes,
+	}, "", "  ")
+}
+
+func UnmarshalProgram(data []byte) (statements []Stmt, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			decodeErr, ok := r.(*astDecodeError)
+			if !ok {
+				panic(r)
+			}
+			statements, err = nil, decodeErr
+		}
+	}()
+
+	var document astDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Version != ASTSchemaVersion {
+		return nil, &astDecodeError{fmt.Sprintf("unsupported schema version %d", document.Version)}
+	}
+
+	decoder := astDecoder{}
+	statements = make([]Stmt, 0, len(document.Statements))
+	for _, raw := range document.Statements {
+		stmt := decoder.stmt(raw)
+		if stmt == nil {
+			decoder.fail("statement can't be null")
+		}
+		statements = append(statements, stmt)
+	}
+	WalkProgram(astValidator{}, statements)
+
+	return statements, nil
+}
+
+// astValidator checks what the parser guarantees but the schema can't:
+// return and yield only appear inside functions, and only generators yield.
+// A generator that doesn't yield is fine; the optimizer can remove its
+// yields, and it is still a generator.
+type astValidator struct {
+	function *FunctionStmt
+}
+
+func (v astValidator) Visit(node Node) Visitor {
+	switch n := node.(type) {
+	case *FunctionStmt:
+		if n.Async && n.Generator {
+			astDecoder{}.fail(fmt.Sprintf("function %q can't be both async and a generator", string(n.Name.Lexeme())))
+		}
+		return astValidator{function: n}
+	case *ReturnStmt:
+		if v.function == nil {
+			astDecoder{}.fail("return outside of a function")
+		}
+	case *YieldStmt:
+		if v.function == nil {
+			astDecoder{}.fail("yield outside of a function")
+		}
+		if v.function.Async {
+			astDecoder{}.fail(fmt.Sprintf("async function %q can't yield", string(v.function.Name.Lexeme())))
+		}
+		if !v.function.Generator {
+			astDecoder{}.fail(fmt.Sprintf("function %q yields but isn't a generator", string(v.function.Name.Lexeme())))
+		}
+	}
+
+	return v
+}
+
+type astEncoder struct{}
+
+func (e astEncoder) VisitAssign(expr *Assign) interface{} {
+	return astNode{"node": "Assign", "name": e.token(expr.Name), "value": e.expr(expr.Value)}
+}
+
+func (e astEncoder) VisitAwait(expr *Await) interface{} {
+	return astNode{"node": "Await", "keyword": e.token(expr.Keyword), "value": e.expr(expr.Value)}
+}
+
+func (e astEncoder) VisitBinary(expr *Binary) interface{} {
+	return astNode{
+		"node":     "Binary",
+		"left":     e.expr(expr.Left),
+		"operator": e.token(expr.Operator),
+		"right":    e.expr(expr.Right),
+	}
+}
+
+func (e astEncoder) VisitCall(expr *Call) interface{} {
+	return astNode{
+		"node":      "Call",
+		"callee":    e.expr(expr.Callee),
+		"operator":  e.token(expr.Operator),
+		"arguments": e.exprs(expr.Arguments),
+	}
+}
+
+func (e astEncoder) VisitGet(expr *Get) interface{} {
+	return astNode{"node": "Get", "object": e.expr(expr.Object), "name": e.token(expr.Name)}
+}
+
+func (e astEncoder) VisitGrouping(expr *Grouping) interface{} {
+	return astNode{"node": "Grouping", "expression": e.expr(expr.Expression)}
+}
+
+func (e astEncoder) VisitLiteral(expr *Literal) interface{} {
+	return astNode{"node": "Literal", "value": expr.Value, "token": e.token(expr.Token)}
+}
+
+func (e astEncoder) VisitLogical(expr *Logical) interface{} {
+	return astNode{
+		"node":     "Logical",
+		"left":     e.expr(expr.Left),
+		"operator": e.token(expr.Operator),
+		"right":    e.expr(expr.Right),
+	}
+}
+
+func (e astEncoder) VisitUnary(expr *Unary) interface{} {
+	return astNode{"node": "Unary", "operator": e.token(expr.Operator), "right": e.expr(expr.Right)}
+}
+
+func (e astEncoder) VisitVariable(expr *Variable) interface{} {
+	return astNode{"node": "Variable", "name": e.token(expr.Name)}
+}
+
+func (e astEncoder) VisitExprStmt(stmt *ExprStmt) interface{} {
+	return astNode{"node": "ExprStmt", "expression": e.expr(stmt.Expression)}
+}
+
+func (e astEncoder) VisitFunctionStmt(stmt *FunctionStmt) interface{} {
+	params := make([]interface{}, 0, len(stmt.Params))
+	for _, param := range stmt.Params {
+		params = append(params, e.token(param))
+	}
+
+	return astNode{
+		"node":      "FunctionStmt",
+		"name":      e.token(stmt.Name),
+		"params":    params,
+		"body":      e.stmts(stmt.Body),
+		"async":     stmt.Async,
+		"generator": stmt.Generator,
+	}
+}
+
+func (e astEncoder) VisitIfStmt(stmt *IfStmt) interface{} {
+	return astNode{
+		"node":      "IfStmt",
+		"condition": e.expr(stmt.Condition),
+		"then":      e.stmt(stmt.Then),
+		"else":      e.stmt(stmt.Else),
+	}
+}
+
+func (e astEncoder) VisitWhileStmt(stmt *WhileStmt) interface{} {
+	return astNode{"node": "WhileStmt", "condition": e.expr(stmt.Condition), "statement": e.stmt(stmt.Statement)}
+}
+
+func (e astEncoder) VisitVarDeclStmt(stmt *VarDeclStmt) interface{} {
+	return astNode{"node": "VarDeclStmt", "name": e.token(stmt.Name), "initializer": e.expr(stmt.Initializer)}
+}
+
+func (e astEncoder) VisitBlockStmt(stmt *BlockStmt) interface{} {
+	return astNode{"node": "BlockStmt", "statements": e.stmts(stmt.Statements), "brace": e.token(stmt.Brace)}
+}
+
+func (e astEncoder) VisitReturnStmt(stmt *ReturnStmt) interface{} {
+	return astNode{"node": "ReturnStmt", "keyword": e.token(stmt.Keyword), "value": e.expr(stmt.Value)}
+}
+
+func (e astEncoder) VisitPrintStmt(stmt *PrintStmt) interface{} {
+	return astNode{"node": "PrintStmt", "expression": e.expr(stmt.Expression)}
+}
+
+func (e astEncoder) VisitSpawnStmt(stmt *SpawnStmt) interface{} {
+	return astNode{"node": "SpawnStmt", "keyword": e.token(stmt.Keyword), "call": e.expr(stmt.Call)}
+}
+
+func (e astEncoder) VisitSelectStmt(stmt *SelectStmt) interface{} {
+	cases := make([]interface{}, 0, len(stmt.Cases))
+	for _, c := range stmt.Cases {
+		cases = append(cases, astNode{
+			"name":      e.token(c.Name),
+			"operation": e.expr(c.Operation),
+			"body":      e.stmt(c.Body),
+		})
+	}
+
+	var defaultBody interface{}
+	if stmt.Default != nil {
+		defaultBody = e.stmt(stmt.Default)
+	}
+
+	return astNode{
+		"node":    "SelectStmt",
+		"keyword": e.token(stmt.Keyword),
+		"cases":   cases,
+		"default": defaultBody,
+	}
+}
+
+func (e astEncoder) VisitYieldStmt(stmt *YieldStmt) interface{} {
+	return astNode{"node": "YieldStmt", "keyword": e.token(stmt.Keyword), "value": e.expr(stmt.Value)}
+}
+
+func (e astEncoder) VisitForInStmt(stmt *ForInStmt) interface{} {
+	return astNode{
+		"node":     "ForInStmt",
+		"name":     e.token(stmt.Name),
+		"iterable": e.expr(stmt.Iterable),
+		"body":     e.stmt(stmt.Body),
+	}
+}
+
+func (e astEncoder) token(token Token) interface{} {
+	if token == nil {
+		return nil
+	}
+
+	return astNode{
+		"type":    token.Type().String(),
+		"lexeme":  string(token.Lexeme()),
+		"literal": token.Literal(),
+		"line":    token.Line(),
+	}
+}
+
+func (e astEncoder) expr(expr Expr) interface{} {
+	if expr == nil {
+		return nil
+	}
+
+	return expr.AcceptInterface(e)
+}
+
+func (e astEncoder) exprs(exprs []Expr) []interface{} {
+	nodes := make([]interface{}, 0, len(exprs))
+	for _, expr := range exprs {
+		nodes = append(nodes, e.expr(expr))
+	}
+
+	return nodes
+}
+
+func (e astEncoder) stmt(stmt Stmt) interface{} {
+	if stmt == nil {
+		return nil
+	}
+
+	return stmt.AcceptInterface(e)
+}
+
+func (e astEncoder) stmts(stmts []Stmt) []interface{} {
+	nodes := make([]interface{}, 0, len(stmts))
+	for _, stmt := range stmts {
+		nodes = append(nodes, e.stmt(stmt))
+	}
+
+	return nodes
+}
+
+type astDecoder struct{}
+
+func (d astDecoder) fail(msg string) {
+	panic(&astDecodeError{msg})
+}
+
+func (d astDecoder) object(raw json.RawMessage) astObject {
+	var obj astObject
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		d.fail(err.Error())
+	}
+
+	return obj
+}
+
+func (d astDecoder) value(obj astObject, key string, target interface{}) {
+	raw, ok := obj[key]
+	if !ok {
+		d.fail(fmt.Sprintf("missing field %q", key))
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		d.fail(fmt.Sprintf("field %q: %v", key, err))
+	}
+}
+
+func (d astDecoder) list(obj astObject, key string) []json.RawMessage {
+	var raws []json.RawMessage
+	d.value(obj, key, &raws)
+
+	return raws
+}
+
+func (d astDecoder) token(obj astObject, key string) Token {
+	var raw json.RawMessage
+	d.value(obj, key, &raw)
+
+	return d.rawToken(raw)
+}
+
+func (d astDecoder) rawToken(raw json.RawMessage) Token {
+	fields := d.object(raw)
+	if fields == nil {
+		return nil
+	}
+
+	var typeName, lexeme string
+	var literal interface{}
+	var line int
+	d.value(fields, "type", &typeName)
+	d.value(fields, "lexeme", &lexeme)
+	d.value(fields, "literal", &literal)
+	d.value(fields, "line", &line)
+
+	tokenType, ok := tokenTypes[typeName]
+	if !ok {
+		d.fail(fmt.Sprintf("unknown token type %q", typeName))
+	}
+
+	return NewToken(tokenType, []rune(lexeme), literal, line)
+}
+
+func (d astDecoder) requiredToken(obj astObject, key string) Token {
+	token := d.token(obj, key)
+	if token == nil {
+		d.fail(fmt.Sprintf("field %q can't be null", key))
+	}
+
+	return token
+}
+
+func (d astDecoder) field(obj astObject, key string) Expr {
+	var raw json.RawMessage
+	d.value(obj, key, &raw)
+
+	return d.expr(raw)
+}
+
+func (d astDecoder) requiredField(obj astObject, key string) Expr {
+	expr := d.field(obj, key)
+	if expr == nil {
+		d.fail(fmt.Sprintf("field %q can't be null", key))
+	}
+
+	return expr
+}
+
+func (d astDecoder) callField(obj astObject, key string) *Call {
+	call, ok := d.requiredField(obj, key).(*Call)
+	if !ok {
+		d.fail(fmt.Sprintf("field %q must be a Call", key))
+	}
+
+	return call
+}
+
+func (d astDecoder) stmtField(obj astObject, key string) Stmt {
+	var raw json.RawMessage
+	d.value(obj, key, &raw)
+
+	return d.stmt(raw)
+}
+
+func (d astDecoder) requiredStmtField(obj astObject, key string) Stmt {
+	stmt := d.stmtField(obj, key)
+	if stmt == nil {
+		d.fail(fmt.Sprintf("field %q can't be null", key))
+	}
+
+	return stmt
+}
+
+func (d astDecoder) blockField(obj astObject, key string) *BlockStmt {
+	stmt := d.stmtField(obj, key)
+	if stmt == nil {
+		return nil
+	}
+
+	block, ok := stmt.(*BlockStmt)
+	if !ok {
+		d.fail(fmt.Sprintf("field %q must be a BlockStmt", key))
+	}
+
+	return block
+}
+
+func (d astDecoder) stmtList(obj astObject, key string) []Stmt {
+	raws := d.list(obj, key)
+	statements := make([]Stmt, 0, len(raws))
+	for _, raw := range raws {
+		stmt := d.stmt(raw)
+		if stmt == nil {
+			d.fail(fmt.Sprintf("field %q can't contain null", key))
+		}
+		statements = append(statements, stmt)
+	}
+
+	return statements
+}
+
+func (d astDecoder) expr(raw json.RawMessage) Expr {
+	obj := d.object(raw)
+	if obj == nil {
+		return nil
+	}
+
+	var node string
+	d.value(obj, "node", &node)
+
+	switch node {
+	case "Assign":
+		return &Assign{Name: d.requiredToken(obj, "name"), Value: d.requiredField(obj, "value")}
+	case "Await":
+		return &Await{Keyword: d.requiredToken(obj, "keyword"), Value: d.requiredField(obj, "value")}
+	case "Binary":
+		return &Binary{
+			Left:     d.requiredField(obj, "left"),
+			Operator: d.requiredToken(obj, "operator"),
+			Right:    d.requiredField(obj, "right"),
+		}
+	case "Call":
+		raws := d.list(obj, "arguments")
+		args := make([]Expr, 0, len(raws))
+		for _, arg := range raws {
+			expr := d.expr(arg)
+			if expr == nil {
+				d.fail("field \"arguments\" can't contain null")
+			}
+			args = append(args, expr)
+		}
+		return &Call{
+			Callee:    d.requiredField(obj, "callee"),
+			Operator:  d.requiredToken(obj, "operator"),
+			Arguments: args,
+		}
+	case "Get":
+		return &Get{Object: d.requiredField(obj, "object"), Name: d.requiredToken(obj, "name")}
+	case "Grouping":
+		return &Grouping{Expression: d.requiredField(obj, "expression")}
+	case "Literal":
+		var value interface{}
+		d.value(obj, "value", &value)
+		switch value.(type) {
+		case nil, bool, float64, string:
+		default:
+			d.fail("literal must be null, a boolean, a number or a string")
+		}
+		return &Literal{Value: value, Token: d.token(obj, "token")}
+	case "Logical":
+		return &Logical{
+			Left:     d.requiredField(obj, "left"),
+			Operator: d.requiredToken(obj, "operator"),
+			Right:    d.requiredField(obj, "right"),
+		}
+	case "Unary":
+		return &Unary{Operator: d.requiredToken(obj, "operator"), Right: d.requiredField(obj, "right")}
+	case "Variable":
+		return &Variable{Name: d.requiredToken(obj, "name")}
+	}
+
+	d.fail(fmt.Sprintf("unknown expression node %q", node))
+	return nil
+}
+
+func (d astDecoder) stmt(raw json.RawMessage) Stmt {
+	obj := d.object(raw)
+	if obj == nil {
+		return nil
+	}
+
+	var node string
+	d.value(obj, "node", &node)
+
+	switch node {
+	case "ExprStmt":
+		return &ExprStmt{Expression: d.requiredField(obj, "expression")}
+	case "FunctionStmt":
+		stmt := &FunctionStmt{
+			Name: d.requiredToken(obj, "name"),
+			Body: d.stmtList(obj, "body"),
+		}
+		for _, raw := range d.list(obj, "params") {
+			param := d.rawToken(raw)
+			if param == nil {
+				d.fail("field \"params\" can't contain null")
+			}
+			stmt.Params = append(stmt.Params, param)
+		}
+		d.value(obj, "async", &stmt.Async)
+		d.value(obj, "generator", &stmt.Generator)
+		return stmt
+	case "IfStmt":
+		return &IfStmt{
+			Condition: d.requiredField(obj, "condition"),
+			Then:      d.requiredStmtField(obj, "then"),
+			Else:      d.stmtField(obj, "else"),
+		}
+	case "WhileStmt":
+		return &WhileStmt{Condition: d.requiredField(obj, "condition"), Statement: d.requiredStmtField(obj, "statement")}
+	case "VarDeclStmt":
+		return &VarDeclStmt{Name: d.requiredToken(obj, "name"), Initializer: d.field(obj, "initializer")}
+	case "BlockStmt":
+		return &BlockStmt{Statements: d.stmtList(obj, "statements"), Brace: d.token(obj, "brace")}
+	case "ReturnStmt":
+		return &ReturnStmt{Keyword: d.requiredToken(obj, "keyword"), Value: d.field(obj, "value")}
+	case "PrintStmt":
+		return &PrintStmt{Expression: d.requiredField(obj, "expression")}
+	case "SpawnStmt":
+		return &SpawnStmt{Keyword: d.requiredToken(obj, "keyword"), Call: d.callField(obj, "call")}
+	case "SelectStmt":
+		stmt := &SelectStmt{
+			Keyword: d.requiredToken(obj, "keyword"),
+			Default: d.blockField(obj, "default"),
+		}
+		for _, raw := range d.list(obj, "cases") {
+			fields := d.object(raw)
+			if fields == nil {
+				d.fail("field \"cases\" can't contain null")
+			}
+			body := d.blockField(fields, "body")
+			if body == nil {
+				d.fail("field \"body\" can't be null")
+			}
+			stmt.Cases = append(stmt.Cases, &SelectCase{
+				Name:      d.token(fields, "name"),
+				Operation: d.callField(fields, "operation"),
+				Body:      body,
+			})
+		}
+		return stmt
+	case "YieldStmt":
+		return &YieldStmt{Keyword: d.requiredToken(obj, "keyword"), Value: d.field(obj, "value")}
+	case "ForInStmt":
+		return &ForInStmt{
+			Name:     d.requiredToken(obj, "name"),
+			Iterable: d.requiredField(obj, "iterable"),
+			Body:     d.requiredStmtField(obj, "body"),
+		}
+	}
+
+	d.fail(fmt.Sprintf("unknown statement node %q", node))
+	return nil
+}
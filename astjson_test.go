@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestASTRoundTrip checks that every testdata/ast program survives
+// MarshalProgram and UnmarshalProgram unchanged, both as JSON and as the
+// printed tree.
+func TestASTRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "ast", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			statements := NewLox(Config{Stderr: io.Discard}).Parse(string(source))
+			if statements == nil {
+				t.Fatal("parse failed")
+			}
+
+			data, err := MarshalProgram(statements)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := UnmarshalProgram(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := MarshalProgram(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(data, again) {
+				t.Errorf("the JSON changed:\n%s\nwant:\n%s", again, data)
+			}
+			if got, want := (ASTPrinter{}).PrintProgram(decoded), (ASTPrinter{}).PrintProgram(statements); got != want {
+				t.Errorf("the tree changed:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestASTRoundTripKeepsGenerators(t *testing.T) {
+	source := `func g() { if (false) yield 1; return 5; } print g();`
+	statements := NewLox(Config{Stderr: io.Discard}).Parse(source)
+	data, err := MarshalProgram(Optimize(statements))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalProgram(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	lox := NewLox(Config{Stdout: &stdout, Stderr: io.Discard})
+	lox.RunProgram(decoded)
+	lox.Close()
+	if want := "<generator g>\n"; stdout.String() != want {
+		t.Errorf("got %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestUnmarshalRejectsInconsistentFunctions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		edit   func(*FunctionStmt)
+		want   string
+	}{
+		{"yield in a plain function", "func f() { yield 1; }", func(f *FunctionStmt) { f.Generator = false }, `function "f" yields but isn't a generator`},
+		{"yield in an async function", "func f() { yield 1; }", func(f *FunctionStmt) { f.Async, f.Generator = true, false }, `async function "f" can't yield`},
+		{"async generator", "func f() {}", func(f *FunctionStmt) { f.Async, f.Generator = true, true }, `function "f" can't be both async and a generator`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements := NewLox(Config{Stderr: io.Discard}).Parse(test.source)
+			test.edit(statements[0].(*FunctionStmt))
+			data, err := MarshalProgram(statements)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = UnmarshalProgram(data)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
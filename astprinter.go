@@ -12,12 +12,23 @@ func (p ASTPrinter) Print(expr Expr) string {
 	return expr.AcceptString(p)
 }
 
+func (p ASTPrinter) PrintProgram(statements []Stmt) string {
+	var builder strings.Builder
+
+	for _, stmt := range statements {
+		builder.WriteString(stmt.AcceptString(p))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
 func (p ASTPrinter) VisitAssign(expr *Assign) string {
 	var builder strings.Builder
 
 	builder.WriteString("(")
 	builder.WriteString(string([]rune("assign")))
-	str := fmt.Sprintf(" %s", string(expr.Name.Lexeme()))
+	str := fmt.Sprintf(" %s ", string(expr.Name.Lexeme()))
 	builder.WriteString(str)
 	builder.WriteString(expr.Value.AcceptString(p))
 	builder.WriteString(")")
@@ -25,6 +36,10 @@ func (p ASTPrinter) VisitAssign(expr *Assign) string {
 	return builder.String()
 }
 
+func (p ASTPrinter) VisitAwait(expr *Await) string {
+	return p.parenthesize([]rune("await"), expr.Value)
+}
+
 func (p ASTPrinter) VisitBinary(expr *Binary) string {
 	return p.parenthesize(expr.Operator.Lexeme(), expr.Left, expr.Right)
 }
@@ -34,6 +49,11 @@ func (p ASTPrinter) VisitCall(expr *Call) string {
 	return p.parenthesize([]rune(name), expr.Arguments...)
 }
 
+func (p ASTPrinter) VisitGet(expr *Get) string {
+	name := fmt.Sprintf("get %s", string(expr.Name.Lexeme()))
+	return p.parenthesize([]rune(name), expr.Object)
+}
+
 func (p ASTPrinter) VisitGrouping(expr *Grouping) string {
 	return p.parenthesize([]rune("group"), expr.Expression)
 }
@@ -45,9 +65,9 @@ func (p ASTPrinter) VisitLiteral(expr *Literal) string {
 
 	switch val := expr.Value.(type) {
 	case float64:
-		return fmt.Sprintf("%f", val)
+		return formatNumber(val)
 	case string:
-		return val
+		return strconv.Quote(val)
 	case bool:
 		return strconv.FormatBool(val)
 	default:
@@ -80,3 +100,118 @@ func (p ASTPrinter) parenthesize(name []rune, expressions ...Expr) string {
 
 	return builder.String()
 }
+
+func (p ASTPrinter) VisitExprStmt(stmt *ExprStmt) string {
+	return stmt.Expression.AcceptString(p)
+}
+
+func (p ASTPrinter) VisitFunctionStmt(stmt *FunctionStmt) string {
+	params := make([]string, 0, len(stmt.Params))
+	for _, param := range stmt.Params {
+		params = append(params, string(param.Lexeme()))
+	}
+
+	kind := "func"
+	if stmt.Async {
+		kind = "async func"
+	} else if stmt.Generator {
+		kind = "generator func"
+	}
+
+	head := fmt.Sprintf("%s %s (%s)", kind, string(stmt.Name.Lexeme()), strings.Join(params, " "))
+	return p.nest(head, p.statements(stmt.Body)...)
+}
+
+func (p ASTPrinter) VisitIfStmt(stmt *IfStmt) string {
+	branches := []string{p.nest("then", stmt.Then.AcceptString(p))}
+	if stmt.Else != nil {
+		branches = append(branches, p.nest("else", stmt.Else.AcceptString(p)))
+	}
+
+	return p.nest("if "+stmt.Condition.AcceptString(p), branches...)
+}
+
+func (p ASTPrinter) VisitWhileStmt(stmt *WhileStmt) string {
+	return p.nest("while "+stmt.Condition.AcceptString(p), stmt.Statement.AcceptString(p))
+}
+
+func (p ASTPrinter) VisitVarDeclStmt(stmt *VarDeclStmt) string {
+	name := string(stmt.Name.Lexeme())
+	if stmt.Initializer == nil {
+		return fmt.Sprintf("(var %s)", name)
+	}
+
+	return fmt.Sprintf("(var %s %s)", name, stmt.Initializer.AcceptString(p))
+}
+
+func (p ASTPrinter) VisitBlockStmt(stmt *BlockStmt) string {
+	return p.nest("block", p.statements(stmt.Statements)...)
+}
+
+func (p ASTPrinter) VisitReturnStmt(stmt *ReturnStmt) string {
+	if stmt.Value == nil {
+		return "(return)"
+	}
+
+	return p.parenthesize([]rune("return"), stmt.Value)
+}
+
+func (p ASTPrinter) VisitPrintStmt(stmt *PrintStmt) string {
+	return p.parenthesize([]rune("print"), stmt.Expression)
+}
+
+func (p ASTPrinter) VisitSpawnStmt(stmt *SpawnStmt) string {
+	return p.parenthesize([]rune("spawn"), stmt.Call)
+}
+
+func (p ASTPrinter) VisitSelectStmt(stmt *SelectStmt) string {
+	cases := make([]string, 0, len(stmt.Cases)+1)
+	for _, c := range stmt.Cases {
+		head := "case " + c.Operation.AcceptString(p)
+		if c.Name != nil {
+			head = fmt.Sprintf("case %s = %s", string(c.Name.Lexeme()), c.Operation.AcceptString(p))
+		}
+		cases = append(cases, p.nest(head, c.Body.AcceptString(p)))
+	}
+	if stmt.Default != nil {
+		cases = append(cases, p.nest("default", stmt.Default.AcceptString(p)))
+	}
+
+	return p.nest("select", cases...)
+}
+
+func (p ASTPrinter) VisitYieldStmt(stmt *YieldStmt) string {
+	if stmt.Value == nil {
+		return "(yield)"
+	}
+
+	return p.parenthesize([]rune("yield"), stmt.Value)
+}
+
+func (p ASTPrinter) VisitForInStmt(stmt *ForInStmt) string {
+	head := fmt.Sprintf("for %s in %s", string(stmt.Name.Lexeme()), stmt.Iterable.AcceptString(p))
+	return p.nest(head, stmt.Body.AcceptString(p))
+}
+
+func (p ASTPrinter) statements(statements []Stmt) []string {
+	lines := make([]string, 0, len(statements))
+	for _, stmt := range statements {
+		lines = append(lines, stmt.AcceptString(p))
+	}
+
+	return lines
+}
+
+func (p ASTPrinter) nest(head string, children ...string) string {
+	var builder strings.Builder
+
+	builder.WriteString("(")
+	builder.WriteString(head)
+	for _, child := range children {
+		builder.WriteString("\n  ")
+		builder.WriteString(strings.ReplaceAll(child, "\n", "\n  "))
+	}
+	builder.WriteString(")")
+
+	return builder.String()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// TestParserGolden parses every testdata/ast/*.lox file and compares the
+// printed tree with the .golden file next to it. Run with -update to
+// rewrite the golden files after an intended change.
+func TestParserGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "ast", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			statements := NewLox(Config{Stderr: io.Discard}).Parse(string(source))
+			if statements == nil {
+				t.Fatal("parse failed")
+			}
+			got := ASTPrinter{}.PrintProgram(statements)
+
+			golden := strings.TrimSuffix(path, ".lox") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestPrintLiterals(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "nil"},
+		{true, "true"},
+		{2.0, "2"},
+		{0.5, "0.5"},
+		{1e21, "1e+21"},
+		{"k", `"k"`},
+		{"say \"hi\"", `"say \"hi\""`},
+	}
+
+	for _, test := range tests {
+		if got := (ASTPrinter{}).Print(&Literal{Value: test.value}); got != test.want {
+			t.Errorf("Print(%#v) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
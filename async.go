@@ -0,0 +1,376 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errTaskCancelled = errors.New("async task cancelled")
+
+type Promise struct {
+	mu       sync.Mutex
+	settled  bool
+	handled  bool
+	value    Value
+	err      *RuntimeError
+	trace    []string
+	onSettle []func()
+}
+
+func NewPromise() *Promise {
+	return &Promise{}
+}
+
+func (p *Promise) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case !p.settled:
+		return "<promise: pending>"
+	case p.err != nil:
+		return "<promise: rejected>"
+	default:
+		return "<promise: fulfilled>"
+	}
+}
+
+func (p *Promise) resolve(value Value) {
+	p.settle(value, nil, nil)
+}
+
+func (p *Promise) reject(err *RuntimeError, trace []string) {
+	p.settle(Nil, err, trace)
+}
+
+func (p *Promise) settle(value Value, err *RuntimeError, trace []string) {
+	p.mu.Lock()
+	if p.settled {
+		p.mu.Unlock()
+		return
+	}
+	p.settled = true
+	p.value, p.err, p.trace = value, err, trace
+	callbacks := p.onSettle
+	p.onSettle = nil
+	p.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+func (p *Promise) then(callback func()) {
+	p.mu.Lock()
+	if !p.settled {
+		p.onSettle = append(p.onSettle, callback)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+
+	callback()
+}
+
+func (p *Promise) isSettled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.settled
+}
+
+func (p *Promise) result() (Value, *RuntimeError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handled = true
+	return p.value, p.err
+}
+
+type asyncTask struct {
+	resume     chan struct{}
+	yield      chan struct{}
+	cancel     chan struct{}
+	panicValue interface{}
+}
+
+type timer struct {
+	deadline time.Time
+	seq      int
+	job      func()
+}
+
+type EventLoop struct {
+	mu       sync.Mutex
+	ready    []func()
+	timers   []*timer
+	seq      int
+	virtual  bool
+	now      time.Time
+	parked   map[*asyncTask]struct{}
+	rejected []*Promise
+}
+
+func NewEventLoop(virtual bool) *EventLoop {
+	return &EventLoop{
+		virtual: virtual,
+		now:     time.Now(),
+		parked:  map[*asyncTask]struct{}{},
+	}
+}
+
+func (l *EventLoop) enqueue(job func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ready = append(l.ready, job)
+}
+
+func (l *EventLoop) schedule(delay time.Duration, job func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now
+	if !l.virtual {
+		now = time.Now()
+	}
+	t := &timer{deadline: now.Add(delay), seq: l.seq, job: job}
+	l.seq++
+
+	idx := sort.Search(len(l.timers), func(k int) bool {
+		other := l.timers[k]
+		return other.deadline.After(t.deadline) || (other.deadline.Equal(t.deadline) && other.seq > t.seq)
+	})
+	l.timers = append(l.timers, nil)
+	copy(l.timers[idx+1:], l.timers[idx:])
+	l.timers[idx] = t
+}
+
+func (l *EventLoop) runOnce() bool {
+	l.mu.Lock()
+	if len(l.ready) > 0 {
+		job := l.ready[0]
+		l.ready = l.ready[1:]
+		l.mu.Unlock()
+
+		job()
+		return true
+	}
+
+	if len(l.timers) == 0 {
+		l.mu.Unlock()
+		return false
+	}
+
+	next := l.timers[0]
+	l.timers = l.timers[1:]
+	if l.virtual && next.deadline.After(l.now) {
+		l.now = next.deadline
+	}
+	l.mu.Unlock()
+
+	if !l.virtual {
+		time.Sleep(time.Until(next.deadline))
+	}
+	next.job()
+	return true
+}
+
+func (l *EventLoop) drain(reporter *ErrorReporter) {
+	for l.runOnce() {
+	}
+
+	l.mu.Lock()
+	rejected := l.rejected
+	l.rejected = nil
+	l.mu.Unlock()
+
+	for _, promise := range rejected {
+		promise.mu.Lock()
+		handled, err, trace := promise.handled, promise.err, promise.trace
+		promise.mu.Unlock()
+
+		if !handled {
+			reporter.EmitRuntimeError(err, trace)
+		}
+	}
+}
+
+func (l *EventLoop) shutdown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for t := range l.parked {
+		close(t.cancel)
+	}
+	l.parked = map[*asyncTask]struct{}{}
+	l.ready = nil
+	l.timers = nil
+	l.rejected = nil
+}
+
+func (l *EventLoop) startTask(run func(t *asyncTask)) {
+	l.enqueue(func() {
+		t := &asyncTask{
+			resume: make(chan struct{}),
+			yield:  make(chan struct{}),
+			cancel: make(chan struct{}),
+		}
+
+		go func() {
+			<-t.resume
+			defer func() {
+				if r := recover(); r != nil {
+					if r == errTaskCancelled {
+						return
+					}
+					t.panicValue = r
+				}
+				t.yield <- struct{}{}
+			}()
+
+			run(t)
+		}()
+
+		l.switchTo(t)
+	})
+}
+
+func (l *EventLoop) switchTo(t *asyncTask) {
+	t.resume <- struct{}{}
+	<-t.yield
+
+	if t.panicValue != nil {
+		panic(t.panicValue)
+	}
+}
+
+func (l *EventLoop) park(t *asyncTask, promise *Promise) {
+	l.mu.Lock()
+	l.parked[t] = struct{}{}
+	l.mu.Unlock()
+
+	promise.then(func() {
+		l.enqueue(func() {
+			l.mu.Lock()
+			delete(l.parked, t)
+			l.mu.Unlock()
+
+			l.switchTo(t)
+		})
+	})
+
+	t.yield <- struct{}{}
+	select {
+	case <-t.resume:
+	case <-t.cancel:
+		panic(errTaskCancelled)
+	}
+}
+
+func (l *EventLoop) reject(promise *Promise, err *RuntimeError, trace []string) {
+	promise.reject(err, trace)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rejected = append(l.rejected, promise)
+}
+
+func (i *Interpreter) startAsync(run func(child *Interpreter) Value) *Promise {
+	promise := NewPromise()
+	depth := i.depth()
+
+	i.loop.startTask(func(t *asyncTask) {
+		child := i.fork()
+		child.baseDepth = depth
+		child.task = t
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(*RuntimeError)
+				if !ok {
+					panic(r)
+				}
+				i.loop.reject(promise, err, child.stackTrace(err.Token))
+			}
+		}()
+
+		promise.resolve(run(child))
+	})
+
+	return promise
+}
+
+func (i *Interpreter) VisitAwait(expr *Await) Value {
+	value := i.evaluate(expr.Value)
+	promise, ok := value.AsObject().(*Promise)
+	if !ok {
+		return value
+	}
+
+	switch {
+	case i.task != nil:
+		if !promise.isSettled() {
+			i.loop.park(i.task, promise)
+		}
+	case i.spawned:
+		panic(i.error(expr.Keyword, "Can't await inside a spawned call."))
+	case i.generator != nil:
+		panic(i.error(expr.Keyword, "Can't await inside a generator."))
+	default:
+		for !promise.isSettled() {
+			if !i.loop.runOnce() {
+				panic(i.error(expr.Keyword, "Await can never complete: no pending tasks or timers."))
+			}
+		}
+	}
+
+	val, err := promise.result()
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+func defineAsyncNatives(env *GlobalEnvironment) {
+	env.Define("setTimeout", NewObject(NewNativeFunction("setTimeout", 2, nativeSetTimeout)))
+	env.Define("sleep", NewObject(NewNativeFunction("sleep", 1, nativeSleep)))
+}
+
+func nativeSetTimeout(i *Interpreter, args []Value) Value {
+	callback, ok := args[0].AsObject().(Callable)
+	if !ok {
+		panic(i.nativeError("Timeout callback must be callable."))
+	}
+	delay := i.delayArg(args[1])
+	token := i.callSite()
+
+	i.loop.schedule(delay, func() {
+		i.startAsync(func(child *Interpreter) Value {
+			return child.call(callback, []Value{}, token)
+		})
+	})
+
+	return Nil
+}
+
+func nativeSleep(i *Interpreter, args []Value) Value {
+	promise := NewPromise()
+	i.loop.schedule(i.delayArg(args[0]), func() {
+		promise.resolve(Nil)
+	})
+
+	return NewObject(promise)
+}
+
+func (i *Interpreter) delayArg(arg Value) time.Duration {
+	ms, ok := arg.AsNumber()
+	if !ok || ms < 0 {
+		panic(i.nativeError("Delay must be a non-negative number of milliseconds."))
+	}
+
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecursiveAsyncOverflows(t *testing.T) {
+	_, stderr := runSource(t, `
+async func f(n) { return await f(n + 1); }
+print await f(0);
+`)
+	if !strings.HasPrefix(stderr, "Stack overflow.") {
+		t.Errorf("got %q, want a stack overflow", stderr)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"time"
+)
+
+const defaultBenchRuns = 10
+
+type benchResult struct {
+	times  []time.Duration
+	allocs uint64
+	bytes  uint64
+}
+
+func (r benchResult) mean() time.Duration {
+	var total time.Duration
+	for _, t := range r.times {
+		total += t
+	}
+
+	return total / time.Duration(len(r.times))
+}
+
+// stddev is the sample standard deviation of the run times.
+func (r benchResult) stddev() time.Duration {
+	if len(r.times) < 2 {
+		return 0
+	}
+
+	mean := float64(r.mean())
+	var sum float64
+	for _, t := range r.times {
+		sum += (float64(t) - mean) * (float64(t) - mean)
+	}
+
+	return time.Duration(math.Sqrt(sum / float64(len(r.times)-1)))
+}
+
+func benchCommand(args []string) int {
+	flags := newFlagSet("bench")
+	runs := flags.Int("n", defaultBenchRuns, "number of runs")
+	optimize := flags.Bool("O", false, "optimize before running")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageError("bench needs a script")
+	}
+	if *runs < 1 {
+		return usageError("bench needs at least one run")
+	}
+
+	source, ok := readSource(args[0])
+	if !ok {
+		return exitNoInput
+	}
+
+	result, code := benchmark(source, args[1:], *runs, *optimize)
+	if code != exitOK {
+		return code
+	}
+
+	n := uint64(len(result.times))
+	fmt.Printf("runs    %d\n", n)
+	fmt.Printf("mean    %v\n", result.mean())
+	fmt.Printf("stddev  %v\n", result.stddev())
+	fmt.Printf("allocs  %d/run\n", result.allocs/n)
+	fmt.Printf("bytes   %d/run\n", result.bytes/n)
+
+	return exitOK
+}
+
+// benchmark runs source the given number of times, each time on a fresh
+// interpreter whose output is discarded. It stops at the first run that
+// fails and returns the exit code lox would have returned for it.
+func benchmark(source string, args []string, runs int, optimize bool) (benchResult, int) {
+	var result benchResult
+	var before, after runtime.MemStats
+
+	for k := 0; k < runs; k++ {
+		config := cliConfig(args, optimize)
+		config.Stdout = io.Discard
+		lox := NewLox(config)
+
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		lox.Run(source)
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if code := finish(lox); code != exitOK {
+			fmt.Fprintf(os.Stderr, "lox: run %d failed\n", k+1)
+			return result, code
+		}
+
+		result.times = append(result.times, elapsed)
+		result.allocs += after.Mallocs - before.Mallocs
+		result.bytes += after.TotalAlloc - before.TotalAlloc
+	}
+
+	return result, exitOK
+}
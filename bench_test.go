@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchmarkProgram runs one of the reference programs in bench/.
+func benchmarkProgram(b *testing.B, name string) {
+	source, err := os.ReadFile(filepath.Join("bench", name+".lox"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkSource(b, string(source))
+}
+
+func BenchmarkProgramFib(b *testing.B) {
+	benchmarkProgram(b, "fib")
+}
+
+func BenchmarkProgramBinaryTrees(b *testing.B) {
+	benchmarkProgram(b, "binary_trees")
+}
+
+func BenchmarkProgramStringBuilding(b *testing.B) {
+	benchmarkProgram(b, "string_building")
+}
+
+func BenchmarkProgramMethodCalls(b *testing.B) {
+	benchmarkProgram(b, "method_calls")
+}
+
+func BenchmarkProgramZoo(b *testing.B) {
+	benchmarkProgram(b, "zoo")
+}
+
+func BenchmarkProgramLoops(b *testing.B) {
+	benchmarkProgram(b, "loops")
+}
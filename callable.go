@@ -1,12 +1,11 @@
 package main
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
+
+const VariadicArity = -1
 
 type Callable interface {
-	Invoke(interpreter *Interpreter, args []interface{}) interface{}
+	Invoke(interpreter *Interpreter, args []Value) Value
 	Arity() int
 }
 
@@ -16,12 +15,13 @@ func (ClockFunction) Arity() int {
 	return 0
 }
 
-func (ClockFunction) Invoke(i *Interpreter, args []interface{}) interface{} {
-	return float64(time.Now().UnixMilli())
+func (ClockFunction) Invoke(i *Interpreter, args []Value) Value {
+	i.requireCapability(i.capabilities.Clock, "clock")
+	return NewNumber(float64(time.Now().UnixMilli()))
 }
 
 func (ClockFunction) String() string {
-	return "<native func: clock>"
+	return Stringify(NewObject(ClockFunction{}))
 }
 
 type Function struct {
@@ -29,14 +29,43 @@ type Function struct {
 	Closure    *Environment
 }
 
-func (f *Function) Invoke(i *Interpreter, args []interface{}) (val interface{}) {
+type returnValue struct {
+	Value Value
+}
+
+func (f *Function) Invoke(i *Interpreter, args []Value) Value {
+	if f.Definition.Generator {
+		return NewObject(NewGenerator(i, f, args))
+	}
+	if !f.Definition.Async {
+		return f.invokeBody(i, args)
+	}
+
+	frame := callFrame{callee: f, token: i.callSite()}
+	return NewObject(i.startAsync(func(child *Interpreter) Value {
+		child.callStack = append(child.callStack, frame)
+		return f.invokeBody(child, args)
+	}))
+}
+
+func (f *Function) invokeBody(i *Interpreter, args []Value) (val Value) {
+	if i.profiler != nil {
+		i.enterProfile(f.Definition)
+		defer i.exitProfile()
+	}
 	defer func() {
-		recover()
-		val = GlobalEnv.Values["return"]
+		if r := recover(); r != nil {
+			ret, ok := r.(*returnValue)
+			if !ok {
+				panic(r)
+			}
+			val = ret.Value
+		}
 	}()
+	i.allocateEnvironment(i.callSite(), len(f.Definition.Params))
 	env := NewEnvironment(f.Closure)
-	for i, param := range f.Definition.Params {
-		env.Define(string(param.Lexeme()), args[i])
+	for k, param := range f.Definition.Params {
+		env.Define(string(param.Lexeme()), args[k])
 	}
 
 	i.executeBlock(f.Definition.Body, env)
@@ -48,5 +77,5 @@ func (f *Function) Arity() int {
 }
 
 func (f *Function) String() string {
-	return fmt.Sprintf("<func: %s>", string(f.Definition.Name.Lexeme()))
+	return Stringify(NewObject(f))
 }
@@ -0,0 +1,384 @@
+// Command genast generates the Expr and Stmt node types, their visitors and
+// optional helpers from the node list in nodes.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type field struct {
+	Name string
+	Type string
+}
+
+type node struct {
+	Name   string
+	Fields []field
+}
+
+type accept struct {
+	Method string
+	Result string
+}
+
+type family struct {
+	Name  string
+	File  string
+	Nodes []node
+	Parts []node
+}
+
+type fieldKind int
+
+const (
+	kindValue fieldKind = iota
+	kindToken
+	kindTokens
+	kindNode
+	kindNodePtr
+	kindNodes
+	kindParts
+)
+
+type helpers struct {
+	walk      bool
+	pos       bool
+	clone     bool
+	transform bool
+}
+
+func main() {
+	helperList := flag.String("helpers", "", "comma-separated helpers to emit: walk, pos, clone, transform")
+	out := flag.String("out", ".", "directory to write the generated files to")
+	flag.Parse()
+
+	enabled, err := parseHelpers(*helperList)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	header := "// Code generated by \"genast"
+	if *helperList != "" {
+		header += " -helpers=" + *helperList
+	}
+	header += "\"; DO NOT EDIT.\n\npackage main\n"
+
+	for _, fam := range families {
+		write(filepath.Join(*out, fam.File), header, generateFamily(fam, enabled))
+	}
+	if enabled.walk || enabled.pos || enabled.clone || enabled.transform {
+		write(filepath.Join(*out, "node.go"), header, generateHelpers(enabled))
+	} else {
+		os.Remove(filepath.Join(*out, "node.go"))
+	}
+}
+
+func parseHelpers(list string) (helpers, error) {
+	var enabled helpers
+	if list == "" {
+		return enabled, nil
+	}
+
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(name) {
+		case "walk":
+			enabled.walk = true
+		case "pos":
+			enabled.pos = true
+		case "clone":
+			enabled.clone = true
+		case "transform":
+			enabled.transform = true
+		default:
+			return enabled, fmt.Errorf("genast: unknown helper %q", name)
+		}
+	}
+
+	if enabled.transform && !enabled.walk {
+		return enabled, fmt.Errorf("genast: the transform helper needs walk")
+	}
+
+	return enabled, nil
+}
+
+func write(path, header string, body []byte) {
+	src, err := format.Source(append([]byte(header), body...))
+	if err != nil {
+		log.Fatalf("genast: formatting %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generateFamily(fam family, enabled helpers) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "\ntype %sVisitorVoid interface {\n", fam.Name)
+	for _, n := range fam.Nodes {
+		fmt.Fprintf(&b, "\tVisit%s(expr *%s)\n", n.Name, n.Name)
+	}
+	fmt.Fprintf(&b, "}\n\ntype %sVisitor[R any] interface {\n", fam.Name)
+	for _, n := range fam.Nodes {
+		fmt.Fprintf(&b, "\tVisit%s(expr *%s) R\n", n.Name, n.Name)
+	}
+	fmt.Fprintf(&b, "}\n\ntype %s interface {\n", fam.Name)
+	for _, a := range accepts {
+		fmt.Fprintf(&b, "\t%s(visitor %sVisitor[%s]) %s\n", a.Method, fam.Name, a.Result, a.Result)
+	}
+	fmt.Fprintf(&b, "\tAccept(visitor %sVisitorVoid)\n", fam.Name)
+	if enabled.walk {
+		fmt.Fprintf(&b, "\tChildren() []Node\n")
+	}
+	if enabled.pos {
+		fmt.Fprintf(&b, "\tLine() int\n")
+	}
+	if enabled.clone {
+		fmt.Fprintf(&b, "\tClone() %s\n", fam.Name)
+	}
+	if enabled.transform {
+		fmt.Fprintf(&b, "\tTransformChildren(fn func(Node) Node)\n")
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	for _, n := range fam.Nodes {
+		writeStruct(&b, n)
+		for _, a := range accepts {
+			fmt.Fprintf(&b, "\nfunc (e *%s) %s(visitor %sVisitor[%s]) %s {\n", n.Name, a.Method, fam.Name, a.Result, a.Result)
+			fmt.Fprintf(&b, "\treturn visitor.Visit%s(e)\n}\n", n.Name)
+		}
+		fmt.Fprintf(&b, "\nfunc (e *%s) Accept(visitor %sVisitorVoid) {\n", n.Name, fam.Name)
+		fmt.Fprintf(&b, "\tvisitor.Visit%s(e)\n}\n", n.Name)
+	}
+	for _, part := range fam.Parts {
+		writeStruct(&b, part)
+	}
+
+	return b.Bytes()
+}
+
+func writeStruct(b *bytes.Buffer, n node) {
+	fmt.Fprintf(b, "\ntype %s struct {\n", n.Name)
+	for _, f := range n.Fields {
+		fmt.Fprintf(b, "\t%s %s\n", f.Name, f.Type)
+	}
+	fmt.Fprintf(b, "}\n")
+}
+
+func generateHelpers(enabled helpers) []byte {
+	var b bytes.Buffer
+
+	if enabled.transform {
+		fmt.Fprintf(&b, "\nimport \"fmt\"\n")
+	}
+
+	if enabled.walk || enabled.pos {
+		fmt.Fprintf(&b, "\ntype Node interface {\n")
+		if enabled.walk {
+			fmt.Fprintf(&b, "\tChildren() []Node\n")
+		}
+		if enabled.pos {
+			fmt.Fprintf(&b, "\tLine() int\n")
+		}
+		if enabled.transform {
+			fmt.Fprintf(&b, "\tTransformChildren(fn func(Node) Node)\n")
+		}
+		fmt.Fprintf(&b, "}\n")
+	}
+
+	if enabled.transform {
+		fmt.Fprintf(&b, "\nfunc transformMismatch(node Node, want string) string {\n")
+		fmt.Fprintf(&b, "\treturn fmt.Sprintf(\"transform returned %%T where %%s is needed\", node, want)\n}\n")
+	}
+
+	for _, fam := range families {
+		if enabled.clone {
+			writeCloneFuncs(&b, fam.Name)
+		}
+		if enabled.transform {
+			writeTransformFuncs(&b, fam.Name)
+		}
+		for _, n := range fam.Nodes {
+			writeHelpers(&b, n, fam.Name, enabled)
+		}
+		for _, part := range fam.Parts {
+			writeHelpers(&b, part, "*"+part.Name, enabled)
+		}
+	}
+
+	return b.Bytes()
+}
+
+func writeHelpers(b *bytes.Buffer, n node, cloneType string, enabled helpers) {
+	if enabled.walk {
+		writeChildren(b, n)
+	}
+	if enabled.pos {
+		writeLine(b, n)
+	}
+	if enabled.clone {
+		writeClone(b, n, cloneType)
+	}
+	if enabled.transform {
+		writeTransform(b, n)
+	}
+}
+
+func writeChildren(b *bytes.Buffer, n node) {
+	fmt.Fprintf(b, "\nfunc (e *%s) Children() []Node {\n", n.Name)
+
+	var body bytes.Buffer
+	for _, f := range n.Fields {
+		switch kindOf(f) {
+		case kindNode, kindNodePtr:
+			fmt.Fprintf(&body, "\tif e.%s != nil {\n\t\tchildren = append(children, e.%s)\n\t}\n", f.Name, f.Name)
+		case kindNodes:
+			fmt.Fprintf(&body, "\tfor _, child := range e.%s {\n\t\tchildren = append(children, child)\n\t}\n", f.Name)
+		case kindParts:
+			fmt.Fprintf(&body, "\tfor _, part := range e.%s {\n\t\tchildren = append(children, part.Children()...)\n\t}\n", f.Name)
+		}
+	}
+
+	if body.Len() == 0 {
+		fmt.Fprintf(b, "\treturn nil\n}\n")
+		return
+	}
+	fmt.Fprintf(b, "\tvar children []Node\n%s\treturn children\n}\n", body.String())
+}
+
+func writeLine(b *bytes.Buffer, n node) {
+	fmt.Fprintf(b, "\nfunc (e *%s) Line() int {\n", n.Name)
+	for _, f := range n.Fields {
+		switch kindOf(f) {
+		case kindToken:
+			fmt.Fprintf(b, "\tif e.%s != nil {\n\t\treturn e.%s.Line()\n\t}\n", f.Name, f.Name)
+		case kindTokens:
+			fmt.Fprintf(b, "\tif len(e.%s) > 0 {\n\t\treturn e.%s[0].Line()\n\t}\n", f.Name, f.Name)
+		case kindNode, kindNodePtr:
+			fmt.Fprintf(b, "\tif e.%s != nil {\n\t\tif line := e.%s.Line(); line != 0 {\n\t\t\treturn line\n\t\t}\n\t}\n", f.Name, f.Name)
+		case kindNodes, kindParts:
+			fmt.Fprintf(b, "\tfor _, child := range e.%s {\n\t\tif line := child.Line(); line != 0 {\n\t\t\treturn line\n\t\t}\n\t}\n", f.Name)
+		}
+	}
+	fmt.Fprintf(b, "\treturn 0\n}\n")
+}
+
+func writeCloneFuncs(b *bytes.Buffer, fam string) {
+	lower := strings.ToLower(fam)
+	fmt.Fprintf(b, "\nfunc clone%s(%s %s) %s {\n", fam, lower, fam, fam)
+	fmt.Fprintf(b, "\tif %s == nil {\n\t\treturn nil\n\t}\n\treturn %s.Clone()\n}\n", lower, lower)
+	fmt.Fprintf(b, "\nfunc clone%ss(list []%s) []%s {\n", fam, fam, fam)
+	fmt.Fprintf(b, "\tif list == nil {\n\t\treturn nil\n\t}\n")
+	fmt.Fprintf(b, "\tcloned := make([]%s, len(list))\n", fam)
+	fmt.Fprintf(b, "\tfor k, item := range list {\n\t\tcloned[k] = clone%s(item)\n\t}\n\treturn cloned\n}\n", fam)
+}
+
+func writeClone(b *bytes.Buffer, n node, cloneType string) {
+	fmt.Fprintf(b, "\nfunc (e *%s) Clone() %s {\n\tc := *e\n", n.Name, cloneType)
+	for _, f := range n.Fields {
+		switch kindOf(f) {
+		case kindTokens:
+			fmt.Fprintf(b, "\tc.%s = append([]Token(nil), e.%s...)\n", f.Name, f.Name)
+		case kindNode:
+			fmt.Fprintf(b, "\tc.%s = clone%s(e.%s)\n", f.Name, f.Type, f.Name)
+		case kindNodePtr:
+			fmt.Fprintf(b, "\tif e.%s != nil {\n\t\tc.%s = e.%s.Clone().(%s)\n\t}\n", f.Name, f.Name, f.Name, f.Type)
+		case kindNodes:
+			fmt.Fprintf(b, "\tc.%s = clone%ss(e.%s)\n", f.Name, strings.TrimPrefix(f.Type, "[]"), f.Name)
+		case kindParts:
+			fmt.Fprintf(b, "\tif e.%s != nil {\n\t\tc.%s = make(%s, len(e.%s))\n", f.Name, f.Name, f.Type, f.Name)
+			fmt.Fprintf(b, "\t\tfor k, part := range e.%s {\n\t\t\tc.%s[k] = part.Clone()\n\t\t}\n\t}\n", f.Name, f.Name)
+		}
+	}
+	fmt.Fprintf(b, "\treturn &c\n}\n")
+}
+
+func writeTransformFuncs(b *bytes.Buffer, fam string) {
+	fmt.Fprintf(b, "\nfunc to%s(node Node) %s {\n", fam, fam)
+	fmt.Fprintf(b, "\tif node == nil {\n\t\treturn nil\n\t}\n")
+	fmt.Fprintf(b, "\t%s, ok := node.(%s)\n", strings.ToLower(fam), fam)
+	fmt.Fprintf(b, "\tif !ok {\n\t\tpanic(transformMismatch(node, \"%s\"))\n\t}\n", fam)
+	fmt.Fprintf(b, "\treturn %s\n}\n", strings.ToLower(fam))
+	fmt.Fprintf(b, "\nfunc transform%ss(list []%s, fn func(Node) Node) []%s {\n", fam, fam, fam)
+	fmt.Fprintf(b, "\tkept := list[:0]\n")
+	fmt.Fprintf(b, "\tfor _, item := range list {\n")
+	fmt.Fprintf(b, "\t\tif item = to%s(fn(item)); item != nil {\n\t\t\tkept = append(kept, item)\n\t\t}\n\t}\n", fam)
+	fmt.Fprintf(b, "\treturn kept\n}\n")
+}
+
+func writeTransform(b *bytes.Buffer, n node) {
+	fmt.Fprintf(b, "\nfunc (e *%s) TransformChildren(fn func(Node) Node) {\n", n.Name)
+	for _, f := range n.Fields {
+		switch kindOf(f) {
+		case kindNode:
+			fmt.Fprintf(b, "\tif e.%s != nil {\n\t\te.%s = to%s(fn(e.%s))\n\t}\n", f.Name, f.Name, f.Type, f.Name)
+		case kindNodePtr:
+			fmt.Fprintf(b, "\tif e.%s != nil {\n\t\tresult := fn(e.%s)\n", f.Name, f.Name)
+			fmt.Fprintf(b, "\t\tnode, ok := result.(%s)\n", f.Type)
+			fmt.Fprintf(b, "\t\tif !ok && result != nil {\n\t\t\tpanic(transformMismatch(result, \"%s\"))\n\t\t}\n", f.Type)
+			fmt.Fprintf(b, "\t\te.%s = node\n\t}\n", f.Name)
+		case kindNodes:
+			fmt.Fprintf(b, "\te.%s = transform%ss(e.%s, fn)\n", f.Name, strings.TrimPrefix(f.Type, "[]"), f.Name)
+		case kindParts:
+			fmt.Fprintf(b, "\tfor _, part := range e.%s {\n\t\tpart.TransformChildren(fn)\n\t}\n", f.Name)
+		}
+	}
+	fmt.Fprintf(b, "}\n")
+}
+
+func kindOf(f field) fieldKind {
+	switch {
+	case f.Type == "Token":
+		return kindToken
+	case f.Type == "[]Token":
+		return kindTokens
+	case isFamily(f.Type):
+		return kindNode
+	case strings.HasPrefix(f.Type, "[]*") && isPart(f.Type[3:]):
+		return kindParts
+	case strings.HasPrefix(f.Type, "[]") && isFamily(f.Type[2:]):
+		return kindNodes
+	case strings.HasPrefix(f.Type, "*") && isNode(f.Type[1:]):
+		return kindNodePtr
+	default:
+		return kindValue
+	}
+}
+
+func isFamily(name string) bool {
+	for _, fam := range families {
+		if fam.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isNode(name string) bool {
+	for _, fam := range families {
+		for _, n := range fam.Nodes {
+			if n.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPart(name string) bool {
+	for _, fam := range families {
+		for _, part := range fam.Parts {
+			if part.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
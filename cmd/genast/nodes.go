@@ -0,0 +1,47 @@
+package main
+
+var accepts = []accept{
+	{"AcceptString", "string"},
+	{"AcceptInterface", "interface{}"},
+	{"AcceptValue", "Value"},
+}
+
+var families = []family{
+	{
+		Name: "Expr",
+		File: "expr.go",
+		Nodes: []node{
+			{"Assign", []field{{"Name", "Token"}, {"Value", "Expr"}, {"Depth", "int"}, {"Index", "int"}}},
+			{"Await", []field{{"Keyword", "Token"}, {"Value", "Expr"}}},
+			{"Binary", []field{{"Left", "Expr"}, {"Operator", "Token"}, {"Right", "Expr"}}},
+			{"Call", []field{{"Callee", "Expr"}, {"Operator", "Token"}, {"Arguments", "[]Expr"}}},
+			{"Get", []field{{"Object", "Expr"}, {"Name", "Token"}}},
+			{"Grouping", []field{{"Expression", "Expr"}}},
+			{"Literal", []field{{"Value", "interface{}"}, {"Token", "Token"}}},
+			{"Logical", []field{{"Left", "Expr"}, {"Operator", "Token"}, {"Right", "Expr"}}},
+			{"Unary", []field{{"Operator", "Token"}, {"Right", "Expr"}}},
+			{"Variable", []field{{"Name", "Token"}, {"Depth", "int"}, {"Index", "int"}}},
+		},
+	},
+	{
+		Name: "Stmt",
+		File: "stmt.go",
+		Nodes: []node{
+			{"ExprStmt", []field{{"Expression", "Expr"}}},
+			{"FunctionStmt", []field{{"Name", "Token"}, {"Params", "[]Token"}, {"Body", "[]Stmt"}, {"Async", "bool"}, {"Generator", "bool"}}},
+			{"IfStmt", []field{{"Condition", "Expr"}, {"Then", "Stmt"}, {"Else", "Stmt"}}},
+			{"WhileStmt", []field{{"Condition", "Expr"}, {"Statement", "Stmt"}}},
+			{"VarDeclStmt", []field{{"Name", "Token"}, {"Initializer", "Expr"}}},
+			{"BlockStmt", []field{{"Statements", "[]Stmt"}, {"Brace", "Token"}}},
+			{"ReturnStmt", []field{{"Keyword", "Token"}, {"Value", "Expr"}}},
+			{"PrintStmt", []field{{"Expression", "Expr"}}},
+			{"SpawnStmt", []field{{"Keyword", "Token"}, {"Call", "*Call"}}},
+			{"SelectStmt", []field{{"Keyword", "Token"}, {"Cases", "[]*SelectCase"}, {"Default", "*BlockStmt"}}},
+			{"YieldStmt", []field{{"Keyword", "Token"}, {"Value", "Expr"}}},
+			{"ForInStmt", []field{{"Name", "Token"}, {"Iterable", "Expr"}, {"Body", "Stmt"}}},
+		},
+		Parts: []node{
+			{"SelectCase", []field{{"Name", "Token"}, {"Operation", "*Call"}, {"Body", "*BlockStmt"}}},
+		},
+	},
+}
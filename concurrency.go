@@ -0,0 +1,441 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// deadlockDelay is how long every task has to stay blocked, with nothing
+// changing, before the blocked channel operations fail as a deadlock. It
+// covers the moment between a task registering as blocked and actually
+// waiting on its channels.
+const deadlockDelay = 20 * time.Millisecond
+
+const (
+	errDeadlock          = "Deadlock: every task is waiting on a channel."
+	errSpawnedTaskFailed = "Channel operation cancelled: a spawned task failed."
+	errInterpreterClosed = "Channel operation cancelled: the interpreter was closed."
+	errExiting           = "Channel operation cancelled: the script is exiting."
+)
+
+type Channel struct {
+	values chan Value
+	closed int32
+}
+
+func (c *Channel) isClosed() bool {
+	return atomic.LoadInt32(&c.closed) != 0
+}
+
+func (c *Channel) String() string {
+	return fmt.Sprintf("<channel %d/%d>", len(c.values), cap(c.values))
+}
+
+var (
+	sendNative    = NewNativeFunction("send", 2, nativeSend)
+	receiveNative = NewNativeFunction("receive", 1, nativeReceive)
+)
+
+func defineConcurrencyNatives(env *GlobalEnvironment) {
+	env.Define("channel", NewObject(NewNativeFunction("channel", 1, nativeChannel)))
+	env.Define("send", NewObject(sendNative))
+	env.Define("receive", NewObject(receiveNative))
+	env.Define("close", NewObject(NewNativeFunction("close", 1, nativeClose)))
+}
+
+func nativeChannel(i *Interpreter, args []Value) Value {
+	size, ok := args[0].AsNumber()
+	if !ok || size < 0 || size != float64(int(size)) {
+		panic(i.nativeError("Channel capacity must be a non-negative integer."))
+	}
+
+	return NewObject(&Channel{values: make(chan Value, int(size))})
+}
+
+func nativeSend(i *Interpreter, args []Value) Value {
+	ch := i.channelArg(args[0])
+	defer i.recoverClosedChannel()
+	select {
+	case ch.values <- args[1]:
+		return Nil
+	default:
+	}
+
+	wait := &channelWait{sends: []*Channel{ch}}
+	done := i.channels.wait(wait)
+	defer i.channels.unwait(wait)
+	select {
+	case ch.values <- args[1]:
+	case <-done:
+		panic(i.channelCancelled(i.callSite()))
+	}
+
+	return Nil
+}
+
+func nativeReceive(i *Interpreter, args []Value) Value {
+	ch := i.channelArg(args[0])
+	select {
+	case val := <-ch.values:
+		return val
+	default:
+	}
+
+	wait := &channelWait{receives: []*Channel{ch}}
+	done := i.channels.wait(wait)
+	defer i.channels.unwait(wait)
+	select {
+	case val := <-ch.values:
+		return val
+	case <-done:
+		panic(i.channelCancelled(i.callSite()))
+	}
+}
+
+func nativeClose(i *Interpreter, args []Value) Value {
+	ch := i.channelArg(args[0])
+	defer i.recoverClosedChannel()
+	close(ch.values)
+	atomic.StoreInt32(&ch.closed, 1)
+
+	return Nil
+}
+
+func (i *Interpreter) channelArg(arg Value) *Channel {
+	ch, ok := arg.AsObject().(*Channel)
+	if !ok {
+		panic(i.nativeError("Argument must be a channel."))
+	}
+
+	return ch
+}
+
+func (i *Interpreter) recoverClosedChannel() {
+	if r := recover(); r != nil {
+		switch r.(type) {
+		case *RuntimeError, *exitSignal:
+			panic(r)
+		}
+		panic(i.nativeError("Channel is closed."))
+	}
+}
+
+// channelCancelled is what a cancelled channel operation raises: the exit
+// signal when the script is exiting, so that this task unwinds quietly, and
+// otherwise a runtime error with the reason.
+func (i *Interpreter) channelCancelled(token Token) interface{} {
+	if i.exit.stopped() {
+		return i.exit.signal()
+	}
+
+	return i.error(token, i.channels.reason())
+}
+
+func (i *Interpreter) VisitSpawnStmt(stmt *SpawnStmt) {
+	callable, args := i.evaluateCall(stmt.Call)
+	child := i.fork()
+	child.spawned = true
+
+	i.tasks.Add(1)
+	i.channels.start()
+	go func() {
+		defer i.tasks.Done()
+		defer child.channels.stop()
+		defer func() {
+			if r := recover(); r != nil {
+				switch signal := r.(type) {
+				case *RuntimeError:
+					child.reporter.EmitRuntimeError(signal, child.stackTrace(signal.Token))
+					child.channels.cancel(errSpawnedTaskFailed)
+				case *exitSignal:
+					child.requestExit(signal.code)
+				default:
+					panic(r)
+				}
+			}
+		}()
+
+		child.call(callable, args, stmt.Call.Operator)
+	}()
+}
+
+func (i *Interpreter) VisitSelectStmt(stmt *SelectStmt) {
+	cases := make([]reflect.SelectCase, 0, len(stmt.Cases)+1)
+	wait := &channelWait{}
+	for _, c := range stmt.Cases {
+		cases = append(cases, i.selectCase(c.Operation, wait))
+	}
+
+	chosen, received, ok := i.selectChannels(stmt.Keyword, cases, wait, stmt.Default != nil)
+	if chosen == len(stmt.Cases) {
+		i.VisitBlockStmt(stmt.Default)
+		return
+	}
+
+	selected := stmt.Cases[chosen]
+	entries := 0
+	if selected.Name != nil {
+		entries = 1
+	}
+	i.allocateEnvironment(stmt.Keyword, entries)
+	env := NewEnvironment(i.Env)
+	if selected.Name != nil {
+		val := Nil
+		if ok {
+			val = received.Interface().(Value)
+		}
+		env.Define(string(selected.Name.Lexeme()), val)
+	}
+	i.executeBlock(selected.Body.Statements, env)
+}
+
+func (i *Interpreter) selectCase(operation *Call, wait *channelWait) reflect.SelectCase {
+	callable, args := i.evaluateCall(operation)
+	i.checkArity(callable, len(args), operation.Operator)
+
+	switch callable {
+	case sendNative:
+		ch, ok := args[0].AsObject().(*Channel)
+		if !ok {
+			panic(i.error(operation.Operator, "Argument must be a channel."))
+		}
+		wait.sends = append(wait.sends, ch)
+		return reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch.values), Send: reflect.ValueOf(args[1])}
+	case receiveNative:
+		ch, ok := args[0].AsObject().(*Channel)
+		if !ok {
+			panic(i.error(operation.Operator, "Argument must be a channel."))
+		}
+		wait.receives = append(wait.receives, ch)
+		return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch.values)}
+	default:
+		panic(i.error(operation.Operator, "Select case must be a send or receive call."))
+	}
+}
+
+// selectChannels picks a ready case, or returns len(cases) when none is
+// ready and the select has a default. Otherwise it blocks until a case is
+// ready or the channel operations are cancelled.
+func (i *Interpreter) selectChannels(keyword Token, cases []reflect.SelectCase, wait *channelWait, hasDefault bool) (chosen int, received reflect.Value, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r.(type) {
+			case *RuntimeError, *exitSignal:
+				panic(r)
+			}
+			panic(i.error(keyword, "Channel is closed."))
+		}
+	}()
+
+	chosen, received, ok = reflect.Select(append(cases, reflect.SelectCase{Dir: reflect.SelectDefault}))
+	if chosen < len(cases) || hasDefault {
+		return
+	}
+
+	done := i.channels.wait(wait)
+	defer i.channels.unwait(wait)
+	chosen, received, ok = reflect.Select(append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)}))
+	if chosen == len(cases) {
+		panic(i.channelCancelled(keyword))
+	}
+
+	return
+}
+
+func (i *Interpreter) fork() *Interpreter {
+	return &Interpreter{
+		Globals:      i.Globals,
+		maxCallDepth: i.maxCallDepth,
+		memory:       i.memory,
+		capabilities: i.capabilities,
+		stdout:       i.stdout,
+		stdin:        i.stdin,
+		reporter:     i.reporter,
+		tasks:        i.tasks,
+		loop:         i.loop,
+		resources:    i.resources,
+		exit:         i.exit,
+		channels:     i.channels,
+		profiler:     i.profiler,
+	}
+}
+
+type syncWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.out.Write(p)
+}
+
+// channelMonitor lets blocked channel operations fail instead of hanging.
+// It counts the running tasks, which are the script and each spawned call
+// (generators and async functions run in lockstep with the task that
+// resumes them), and the channel operations they are blocked on. Blocked
+// operations are cancelled when every task is blocked and none of them can
+// proceed, when a spawned task fails, or when the interpreter is closed.
+type channelMonitor struct {
+	mu        sync.Mutex
+	running   int
+	waits     map[*channelWait]struct{}
+	epoch     int
+	done      chan struct{}
+	cancelled string
+	closed    bool
+}
+
+type channelWait struct {
+	sends    []*Channel
+	receives []*Channel
+}
+
+func newChannelMonitor() *channelMonitor {
+	return &channelMonitor{
+		waits: map[*channelWait]struct{}{},
+		done:  make(chan struct{}),
+	}
+}
+
+// reset clears a cancellation left over from an earlier run.
+func (m *channelMonitor) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancelled != "" && !m.closed {
+		m.cancelled = ""
+		m.done = make(chan struct{})
+	}
+}
+
+func (m *channelMonitor) start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running++
+	m.epoch++
+}
+
+func (m *channelMonitor) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running--
+	m.epoch++
+	m.checkDeadlock()
+}
+
+func (m *channelMonitor) wait(w *channelWait) <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.waits[w] = struct{}{}
+	m.epoch++
+	m.checkDeadlock()
+
+	return m.done
+}
+
+func (m *channelMonitor) unwait(w *channelWait) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.waits, w)
+	m.epoch++
+}
+
+func (m *channelMonitor) cancel(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cancelLocked(reason)
+}
+
+func (m *channelMonitor) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.cancelLocked(errInterpreterClosed)
+}
+
+func (m *channelMonitor) cancelLocked(reason string) {
+	if m.cancelled == "" {
+		m.cancelled = reason
+		close(m.done)
+	}
+}
+
+func (m *channelMonitor) reason() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cancelled
+}
+
+// checkDeadlock cancels the blocked operations if every task is still
+// blocked, with nothing having changed, after deadlockDelay.
+func (m *channelMonitor) checkDeadlock() {
+	if !m.deadlocked() {
+		return
+	}
+
+	epoch := m.epoch
+	time.AfterFunc(deadlockDelay, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if m.epoch == epoch && m.deadlocked() {
+			m.cancelLocked(errDeadlock)
+		}
+	})
+}
+
+func (m *channelMonitor) deadlocked() bool {
+	if len(m.waits) == 0 || len(m.waits) < m.running {
+		return false
+	}
+
+	for w := range m.waits {
+		for _, ch := range w.sends {
+			if ch.isClosed() || len(ch.values) < cap(ch.values) || m.waitingOn(w, ch, false) {
+				return false
+			}
+		}
+		for _, ch := range w.receives {
+			if ch.isClosed() || len(ch.values) > 0 || m.waitingOn(w, ch, true) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// waitingOn reports whether a task other than the one blocked on w is
+// blocked sending to ch (send is true) or receiving from it.
+func (m *channelMonitor) waitingOn(w *channelWait, ch *Channel, send bool) bool {
+	for other := range m.waits {
+		if other == w {
+			continue
+		}
+		channels := other.receives
+		if send {
+			channels = other.sends
+		}
+		for _, c := range channels {
+			if c == ch {
+				return true
+			}
+		}
+	}
+
+	return false
+}
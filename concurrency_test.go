@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const concurrentSource = `
+func worker(jobs, results) {
+  var job = receive(jobs);
+  while (job != nil) {
+    send(results, job * job);
+    job = receive(jobs);
+  }
+}
+
+var jobs = channel(0);
+var results = channel(10);
+for (var k = 0; k < 3; k = k + 1) spawn worker(jobs, results);
+for (var k = 1; k <= 10; k = k + 1) send(jobs, k);
+close(jobs);
+
+var sum = 0;
+for (var k = 0; k < 10; k = k + 1) sum = sum + receive(results);
+print sum;
+
+func squares(n) {
+  for (var k = 1; k <= n; k = k + 1) yield k * k;
+}
+var total = 0;
+for (var v in squares(10)) total = total + v;
+print total;
+
+async func delayed(v) {
+  await sleep(5);
+  return v;
+}
+async func both() {
+  var a = delayed(20);
+  var b = delayed(22);
+  return await a + await b;
+}
+print await both();
+
+var ch = channel(0);
+select {
+  case receive(ch) { print "unreachable"; }
+  default { print "idle"; }
+}
+`
+
+func TestParallelInterpreters(t *testing.T) {
+	var wg sync.WaitGroup
+	for k := 0; k < 8; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			stdout, stderr := runSource(t, concurrentSource)
+			if stderr != "" {
+				t.Errorf("unexpected error:\n%s", stderr)
+			}
+			if want := "385\n385\n42\nidle\n"; stdout != want {
+				t.Errorf("got %q, want %q", stdout, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParallelInterpretersShareProgram(t *testing.T) {
+	statements := NewLox(Config{}).Parse(concurrentSource)
+	if statements == nil {
+		t.Fatal("parse failed")
+	}
+
+	var wg sync.WaitGroup
+	for k := 0; k < 8; k++ {
+		wg.Add(1)
+		go func(optimize bool) {
+			defer wg.Done()
+
+			var stdout, stderr bytes.Buffer
+			lox := NewLox(Config{
+				Capabilities: FullCapabilities(),
+				Stdout:       &stdout,
+				Stderr:       &stderr,
+				VirtualTime:  true,
+				Optimize:     optimize,
+			})
+			lox.RunProgram(statements)
+			lox.Close()
+
+			if stderr.Len() > 0 {
+				t.Errorf("unexpected error:\n%s", stderr.String())
+			}
+			if want := "385\n385\n42\nidle\n"; stdout.String() != want {
+				t.Errorf("got %q, want %q", stdout.String(), want)
+			}
+		}(k%2 == 0)
+	}
+	wg.Wait()
+}
+
+func TestChannelDeadlock(t *testing.T) {
+	_, stderr := runSource(t, `var ch = channel(0); print receive(ch);`)
+	if !strings.HasPrefix(stderr, errDeadlock+"\n") {
+		t.Errorf("got %q, want a deadlock error", stderr)
+	}
+}
+
+func TestSpawnedFailureCancelsChannels(t *testing.T) {
+	_, stderr := runSource(t, `
+func worker(ch) { var x = nil + 1; send(ch, x); }
+var ch = channel(0);
+spawn worker(ch);
+receive(ch);
+`)
+	if !strings.Contains(stderr, errSpawnedTaskFailed) {
+		t.Errorf("got %q, want the blocked receive to be cancelled", stderr)
+	}
+}
+
+func TestCloseCancelsChannels(t *testing.T) {
+	var stderr strings.Builder
+	lox := NewLox(Config{Stderr: &syncWriter{out: &stderr}})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// The spawned calls keep passing values, so this isn't a deadlock.
+		lox.Run(`
+func ticker(ch) { while (true) send(ch, 1); }
+func sink(ch) { while (true) receive(ch); }
+var ch = channel(0);
+spawn ticker(ch);
+spawn sink(ch);
+receive(channel(0));
+`)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	lox.Close()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close didn't cancel the blocked receive")
+	}
+	if !strings.Contains(fmt.Sprint(&stderr), errInterpreterClosed) {
+		t.Errorf("got %q, want the blocked receive to be cancelled", fmt.Sprint(&stderr))
+	}
+}
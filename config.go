@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+const DefaultMaxCallDepth = 1000
+
+type Config struct {
+	MaxCallDepth int
+	// MaxAllocBytes is the total number of bytes the interpreter may
+	// allocate over its lifetime, or no limit when it is zero. Memory that
+	// becomes garbage is not given back to the budget.
+	MaxAllocBytes int64
+	Capabilities  Capabilities
+	Stdout        io.Writer
+	Stderr        io.Writer
+	Stdin         io.Reader
+	VirtualTime   bool
+	Args          []string
+	Optimize      bool
+	Profiler      *Profiler
+}
+
+func (c Config) maxCallDepth() int {
+	if c.MaxCallDepth <= 0 {
+		return DefaultMaxCallDepth
+	}
+
+	return c.MaxCallDepth
+}
+
+func (c Config) stdout() io.Writer {
+	if c.Stdout == nil {
+		return os.Stdout
+	}
+
+	return c.Stdout
+}
+
+func (c Config) stderr() io.Writer {
+	if c.Stderr == nil {
+		return os.Stderr
+	}
+
+	return c.Stderr
+}
+
+func (c Config) stdin() io.Reader {
+	if c.Stdin == nil {
+		return os.Stdin
+	}
+
+	return c.Stdin
+}
@@ -46,6 +46,14 @@ const (
 	True
 	Var
 	While
+	Spawn
+	Select
+	Case
+	Default
+	Async
+	Await
+	Yield
+	In
 
 	EOF
 )
@@ -46,12 +46,20 @@ func _() {
 	_ = x[True-35]
 	_ = x[Var-36]
 	_ = x[While-37]
-	_ = x[EOF-38]
+	_ = x[Spawn-38]
+	_ = x[Select-39]
+	_ = x[Case-40]
+	_ = x[Default-41]
+	_ = x[Async-42]
+	_ = x[Await-43]
+	_ = x[Yield-44]
+	_ = x[In-45]
+	_ = x[EOF-46]
 }
 
-const _TokenType_name = "LeftParenRightParenLeftBraceRightBraceCommaDotMinusPlusSemicolonSlashStarBangBangEqualEqualEqualEqualGreaterGreaterEqualLessLessEqualIdentifierStringNumberAndClassElseFalseFuncForIfNilOrPrintReturnSuperThisTrueVarWhileEOF"
+const _TokenType_name = "LeftParenRightParenLeftBraceRightBraceCommaDotMinusPlusSemicolonSlashStarBangBangEqualEqualEqualEqualGreaterGreaterEqualLessLessEqualIdentifierStringNumberAndClassElseFalseFuncForIfNilOrPrintReturnSuperThisTrueVarWhileSpawnSelectCaseDefaultAsyncAwaitYieldInEOF"
 
-var _TokenType_index = [...]uint8{0, 9, 19, 28, 38, 43, 46, 51, 55, 64, 69, 73, 77, 86, 91, 101, 108, 120, 124, 133, 143, 149, 155, 158, 163, 167, 172, 176, 179, 181, 184, 186, 191, 197, 202, 206, 210, 213, 218, 221}
+var _TokenType_index = [...]uint16{0, 9, 19, 28, 38, 43, 46, 51, 55, 64, 69, 73, 77, 86, 91, 101, 108, 120, 124, 133, 143, 149, 155, 158, 163, 167, 172, 176, 179, 181, 184, 186, 191, 197, 202, 206, 210, 213, 218, 223, 229, 233, 240, 245, 250, 255, 257, 260}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {
@@ -1,56 +1,202 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
+const globalDepth = -1
+
+// Environment holds the values of one local scope in declaration order.
+// Names are kept alongside so that redeclaring a name reuses its slot and a
+// variable whose slot isn't defined yet can still be found by name.
 type Environment struct {
-	Values   map[string]interface{}
+	mu       sync.RWMutex
+	bindings []binding
+	inline   [2]binding
 	OuterEnv *Environment
 }
 
-var GlobalEnv *Environment
-
-func init() {
-	GlobalEnv = NewEnvironment(nil)
-	GlobalEnv.Define("clock", ClockFunction{})
+type binding struct {
+	name  string
+	value Value
 }
 
 func NewEnvironment(outerEnv *Environment) *Environment {
-	return &Environment{
-		Values:   map[string]interface{}{},
+	env := &Environment{
 		OuterEnv: outerEnv,
 	}
+	env.bindings = env.inline[:0]
+
+	return env
 }
 
-func (env *Environment) Define(name string, value interface{}) {
-	env.Values[name] = value
+func (env *Environment) Define(name string, value Value) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	for k := range env.bindings {
+		if env.bindings[k].name == name {
+			env.bindings[k].value = value
+			return
+		}
+	}
+
+	env.bindings = append(env.bindings, binding{name: name, value: value})
 }
 
-func (env *Environment) Get(name Token) interface{} {
-	val, hasVal := env.Values[string(name.Lexeme())]
-	if hasVal {
+// GetAt returns the value in slot index of the environment depth levels up.
+// The resolver gives every name the slot it has in the innermost scope that
+// declares it anywhere, so the slot may not be defined yet; the name is then
+// looked up in the enclosing environments and the globals, as it would be
+// without slots.
+func (env *Environment) GetAt(depth, index int, name Token, globals *GlobalEnvironment) Value {
+	target := env.ancestor(depth)
+	target.mu.RLock()
+	if index < len(target.bindings) {
+		val := target.bindings[index].value
+		target.mu.RUnlock()
 		return val
 	}
+	target.mu.RUnlock()
 
-	if env.OuterEnv != nil {
-		return env.OuterEnv.Get(name)
+	lexeme := string(name.Lexeme())
+	for outer := target.OuterEnv; outer != nil; outer = outer.OuterEnv {
+		if val, ok := outer.get(lexeme); ok {
+			return val
+		}
 	}
 
-	errMsg := fmt.Sprintf("Undefined variable '%s'.", string(name.Lexeme()))
-	panic(EmitRuntimeError(name, errMsg))
+	return globals.GetByName(name)
 }
 
-func (env *Environment) Assign(name Token, value interface{}) {
-	nameStr := string(name.Lexeme())
-	if _, ok := env.Values[nameStr]; ok {
-		env.Values[nameStr] = value
+func (env *Environment) AssignAt(depth, index int, name Token, value Value, globals *GlobalEnvironment) {
+	target := env.ancestor(depth)
+	target.mu.Lock()
+	if index < len(target.bindings) {
+		target.bindings[index].value = value
+		target.mu.Unlock()
 		return
 	}
+	target.mu.Unlock()
+
+	lexeme := string(name.Lexeme())
+	for outer := target.OuterEnv; outer != nil; outer = outer.OuterEnv {
+		if outer.assign(lexeme, value) {
+			return
+		}
+	}
+
+	globals.AssignByName(name, value)
+}
+
+func (env *Environment) get(name string) (Value, bool) {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+
+	for _, b := range env.bindings {
+		if b.name == name {
+			return b.value, true
+		}
+	}
+
+	return Nil, false
+}
+
+func (env *Environment) assign(name string, value Value) bool {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	for k := range env.bindings {
+		if env.bindings[k].name == name {
+			env.bindings[k].value = value
+			return true
+		}
+	}
+
+	return false
+}
+
+func (env *Environment) ancestor(depth int) *Environment {
+	target := env
+	for k := 0; k < depth; k++ {
+		target = target.OuterEnv
+	}
+
+	return target
+}
+
+type undefinedValue struct{}
+
+var undefined = NewObject(undefinedValue{})
+
+type GlobalEnvironment struct {
+	mu     sync.RWMutex
+	slots  map[string]int
+	values []Value
+}
+
+func NewGlobalEnvironment() *GlobalEnvironment {
+	return &GlobalEnvironment{
+		slots: map[string]int{},
+	}
+}
+
+func (g *GlobalEnvironment) Slot(name string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.slot(name)
+}
+
+func (g *GlobalEnvironment) slot(name string) int {
+	index, ok := g.slots[name]
+	if !ok {
+		index = len(g.values)
+		g.slots[name] = index
+		g.values = append(g.values, undefined)
+	}
+
+	return index
+}
+
+func (g *GlobalEnvironment) Define(name string, value Value) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.values[g.slot(name)] = value
+}
+
+func (g *GlobalEnvironment) Get(index int, name Token) Value {
+	g.mu.RLock()
+	val := g.values[index]
+	g.mu.RUnlock()
+
+	if _, ok := val.ref.(undefinedValue); ok {
+		errMsg := fmt.Sprintf("Undefined variable '%s'.", string(name.Lexeme()))
+		panic(NewRuntimeError(name, errMsg))
+	}
+
+	return val
+}
+
+func (g *GlobalEnvironment) GetByName(name Token) Value {
+	return g.Get(g.Slot(string(name.Lexeme())), name)
+}
+
+func (g *GlobalEnvironment) AssignByName(name Token, value Value) {
+	g.Assign(g.Slot(string(name.Lexeme())), name, value)
+}
 
-	if env.OuterEnv != nil {
-		env.OuterEnv.Assign(name, value)
+func (g *GlobalEnvironment) Assign(index int, name Token, value Value) {
+	g.mu.Lock()
+	if _, ok := g.values[index].ref.(undefinedValue); !ok {
+		g.values[index] = value
+		g.mu.Unlock()
 		return
 	}
+	g.mu.Unlock()
 
-	msg := fmt.Sprintf("Undefined variable '%s'.", nameStr)
-	panic(EmitRuntimeError(name, msg))
+	msg := fmt.Sprintf("Undefined variable '%s'.", string(name.Lexeme()))
+	panic(NewRuntimeError(name, msg))
 }
@@ -2,40 +2,97 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"strings"
+	"sync"
 
 	tokentype "github.com/roycefanproxy/yaglox/constant"
 )
 
-var (
-	HasError        = false
-	HasRuntimeError = false
-)
+type RuntimeError struct {
+	Token   Token
+	Message string
+}
+
+func NewRuntimeError(token Token, msg string) *RuntimeError {
+	return &RuntimeError{
+		Token:   token,
+		Message: msg,
+	}
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s\n[line %v]", e.Message, e.Token.Line())
+}
+
+type ErrorReporter struct {
+	mu              sync.Mutex
+	out             io.Writer
+	hasError        bool
+	hasRuntimeError bool
+}
+
+func NewErrorReporter(out io.Writer) *ErrorReporter {
+	return &ErrorReporter{
+		out: out,
+	}
+}
 
-func EmitErrorLog(line int, msg string) {
-	report(line, "", msg)
+func (r *ErrorReporter) HasError() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.hasError
+}
+
+func (r *ErrorReporter) HasRuntimeError() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.hasRuntimeError
+}
+
+func (r *ErrorReporter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hasError = false
+	r.hasRuntimeError = false
+}
+
+func (r *ErrorReporter) EmitErrorLog(line int, msg string) {
+	r.report(line, "", msg)
 }
 
-func report(line int, location, msg string) string {
+func (r *ErrorReporter) report(line int, location, msg string) string {
 	errMsg := fmt.Sprintf("[line %d] Error %s: %s", line, location, msg)
-	fmt.Fprintln(os.Stderr, errMsg)
-	HasError = true
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintln(r.out, errMsg)
+	r.hasError = true
 	return errMsg
 }
 
-func EmitParseError(token Token, msg string) string {
+func (r *ErrorReporter) EmitParseError(token Token, msg string) string {
 	loc := " at end"
 	if token.Type() != tokentype.EOF {
 		loc = fmt.Sprintf("at ' %s'", string(token.Lexeme()))
 	}
 
-	return report(token.Line(), loc, msg)
+	return r.report(token.Line(), loc, msg)
 }
 
-func EmitRuntimeError(token Token, msg string) string {
-	str := fmt.Sprintf("%s\n[line %v]", msg, token.Line())
-	HasRuntimeError = true
-	fmt.Println(str)
+func (r *ErrorReporter) EmitRuntimeError(err *RuntimeError, trace []string) string {
+	str := err.Error()
+	if len(trace) > 0 {
+		str = fmt.Sprintf("%s\n%s", err.Message, strings.Join(trace, "\n"))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hasRuntimeError = true
+	fmt.Fprintln(r.out, str)
 
 	return str
 }
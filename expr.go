@@ -1,22 +1,26 @@
+// Code generated by "genast -helpers=walk,pos,clone,transform"; DO NOT EDIT.
 
 package main
 
-
 type ExprVisitorVoid interface {
-    VisitAssign(expr *Assign) 
-	VisitBinary(expr *Binary) 
-	VisitCall(expr *Call) 
-	VisitGrouping(expr *Grouping) 
-	VisitLiteral(expr *Literal) 
-	VisitLogical(expr *Logical) 
-	VisitUnary(expr *Unary) 
-	VisitVariable(expr *Variable) 
+	VisitAssign(expr *Assign)
+	VisitAwait(expr *Await)
+	VisitBinary(expr *Binary)
+	VisitCall(expr *Call)
+	VisitGet(expr *Get)
+	VisitGrouping(expr *Grouping)
+	VisitLiteral(expr *Literal)
+	VisitLogical(expr *Logical)
+	VisitUnary(expr *Unary)
+	VisitVariable(expr *Variable)
 }
 
 type ExprVisitor[R any] interface {
-    VisitAssign(expr *Assign) R
+	VisitAssign(expr *Assign) R
+	VisitAwait(expr *Await) R
 	VisitBinary(expr *Binary) R
 	VisitCall(expr *Call) R
+	VisitGet(expr *Get) R
 	VisitGrouping(expr *Grouping) R
 	VisitLiteral(expr *Literal) R
 	VisitLogical(expr *Logical) R
@@ -25,153 +29,227 @@ type ExprVisitor[R any] interface {
 }
 
 type Expr interface {
-    AcceptString(visitor ExprVisitor[string]) string
+	AcceptString(visitor ExprVisitor[string]) string
 	AcceptInterface(visitor ExprVisitor[interface{}]) interface{}
-	Accept(visitor ExprVisitorVoid) 
+	AcceptValue(visitor ExprVisitor[Value]) Value
+	Accept(visitor ExprVisitorVoid)
+	Children() []Node
+	Line() int
+	Clone() Expr
+	TransformChildren(fn func(Node) Node)
 }
 
 type Assign struct {
-    Name Token
+	Name  Token
 	Value Expr
+	Depth int
+	Index int
 }
 
 func (e *Assign) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitAssign(e)
+	return visitor.VisitAssign(e)
 }
 
 func (e *Assign) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitAssign(e)
+	return visitor.VisitAssign(e)
 }
 
-func (e *Assign) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitAssign(e)
+func (e *Assign) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitAssign(e)
 }
 
+func (e *Assign) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitAssign(e)
+}
+
+type Await struct {
+	Keyword Token
+	Value   Expr
+}
+
+func (e *Await) AcceptString(visitor ExprVisitor[string]) string {
+	return visitor.VisitAwait(e)
+}
+
+func (e *Await) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
+	return visitor.VisitAwait(e)
+}
+
+func (e *Await) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitAwait(e)
+}
+
+func (e *Await) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitAwait(e)
+}
 
 type Binary struct {
-    Left Expr
+	Left     Expr
 	Operator Token
-	Right Expr
+	Right    Expr
 }
 
 func (e *Binary) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitBinary(e)
+	return visitor.VisitBinary(e)
 }
 
 func (e *Binary) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitBinary(e)
+	return visitor.VisitBinary(e)
 }
 
-func (e *Binary) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitBinary(e)
+func (e *Binary) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitBinary(e)
 }
 
+func (e *Binary) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitBinary(e)
+}
 
 type Call struct {
-    Callee Expr
-	Operator Token
+	Callee    Expr
+	Operator  Token
 	Arguments []Expr
 }
 
 func (e *Call) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitCall(e)
+	return visitor.VisitCall(e)
 }
 
 func (e *Call) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitCall(e)
+	return visitor.VisitCall(e)
+}
+
+func (e *Call) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitCall(e)
 }
 
-func (e *Call) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitCall(e)
+func (e *Call) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitCall(e)
 }
 
+type Get struct {
+	Object Expr
+	Name   Token
+}
+
+func (e *Get) AcceptString(visitor ExprVisitor[string]) string {
+	return visitor.VisitGet(e)
+}
+
+func (e *Get) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
+	return visitor.VisitGet(e)
+}
+
+func (e *Get) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitGet(e)
+}
+
+func (e *Get) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitGet(e)
+}
 
 type Grouping struct {
-    Expression Expr
+	Expression Expr
 }
 
 func (e *Grouping) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitGrouping(e)
+	return visitor.VisitGrouping(e)
 }
 
 func (e *Grouping) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitGrouping(e)
+	return visitor.VisitGrouping(e)
 }
 
-func (e *Grouping) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitGrouping(e)
+func (e *Grouping) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitGrouping(e)
 }
 
+func (e *Grouping) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitGrouping(e)
+}
 
 type Literal struct {
-    Value interface{}
+	Value interface{}
+	Token Token
 }
 
 func (e *Literal) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitLiteral(e)
+	return visitor.VisitLiteral(e)
 }
 
 func (e *Literal) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitLiteral(e)
+	return visitor.VisitLiteral(e)
 }
 
-func (e *Literal) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitLiteral(e)
+func (e *Literal) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitLiteral(e)
 }
 
+func (e *Literal) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitLiteral(e)
+}
 
 type Logical struct {
-    Left Expr
+	Left     Expr
 	Operator Token
-	Right Expr
+	Right    Expr
 }
 
 func (e *Logical) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitLogical(e)
+	return visitor.VisitLogical(e)
 }
 
 func (e *Logical) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitLogical(e)
+	return visitor.VisitLogical(e)
 }
 
-func (e *Logical) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitLogical(e)
+func (e *Logical) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitLogical(e)
 }
 
+func (e *Logical) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitLogical(e)
+}
 
 type Unary struct {
-    Operator Token
-	Right Expr
+	Operator Token
+	Right    Expr
 }
 
 func (e *Unary) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitUnary(e)
+	return visitor.VisitUnary(e)
 }
 
 func (e *Unary) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitUnary(e)
+	return visitor.VisitUnary(e)
 }
 
-func (e *Unary) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitUnary(e)
+func (e *Unary) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitUnary(e)
 }
 
+func (e *Unary) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitUnary(e)
+}
 
 type Variable struct {
-    Name Token
+	Name  Token
+	Depth int
+	Index int
 }
 
 func (e *Variable) AcceptString(visitor ExprVisitor[string]) string {
-    return visitor.VisitVariable(e)
+	return visitor.VisitVariable(e)
 }
 
 func (e *Variable) AcceptInterface(visitor ExprVisitor[interface{}]) interface{} {
-    return visitor.VisitVariable(e)
+	return visitor.VisitVariable(e)
 }
 
-func (e *Variable) Accept(visitor ExprVisitorVoid)  {
-    visitor.VisitVariable(e)
+func (e *Variable) AcceptValue(visitor ExprVisitor[Value]) Value {
+	return visitor.VisitVariable(e)
 }
 
-
-
+func (e *Variable) Accept(visitor ExprVisitorVoid) {
+	visitor.VisitVariable(e)
+}
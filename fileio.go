@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+func defineFileNatives(env *GlobalEnvironment) {
+	env.Define("readFile", method("readFile", 1, nativeReadFile))
+	env.Define("writeFile", method("writeFile", 2, nativeWriteFile))
+	env.Define("appendFile", method("appendFile", 2, nativeAppendFile))
+	env.Define("exists", method("exists", 1, nativeExists))
+	env.Define("listDir", method("listDir", 1, nativeListDir))
+	env.Define("mkdir", method("mkdir", 1, nativeMkdir))
+	env.Define("remove", method("remove", 1, nativeRemove))
+	env.Define("open", method("open", 1, nativeOpen))
+}
+
+func nativeReadFile(i *Interpreter, args []Value) Value {
+	path := i.stringArg(args, 0)
+	i.requireRead(path)
+
+	bin, err := os.ReadFile(path)
+	if err != nil {
+		panic(i.ioError("read", path, err))
+	}
+	i.allocateString(i.callSite(), len(bin))
+
+	return NewString(string(bin))
+}
+
+func nativeWriteFile(i *Interpreter, args []Value) Value {
+	path, content := i.stringArg(args, 0), i.stringArg(args, 1)
+	i.requireWrite(path)
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		panic(i.ioError("write", path, err))
+	}
+
+	return Nil
+}
+
+func nativeAppendFile(i *Interpreter, args []Value) Value {
+	path, content := i.stringArg(args, 0), i.stringArg(args, 1)
+	i.requireWrite(path)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		panic(i.ioError("append to", path, err))
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		panic(i.ioError("append to", path, err))
+	}
+
+	return Nil
+}
+
+func nativeExists(i *Interpreter, args []Value) Value {
+	path := i.stringArg(args, 0)
+	i.requireRead(path)
+
+	_, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		panic(i.ioError("stat", path, err))
+	}
+
+	return NewBool(err == nil)
+}
+
+func nativeListDir(i *Interpreter, args []Value) Value {
+	path := i.stringArg(args, 0)
+	i.requireRead(path)
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		panic(i.ioError("list", path, err))
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	return NewObject(i.newList(i.callSite(), toValues(names)))
+}
+
+func nativeMkdir(i *Interpreter, args []Value) Value {
+	path := i.stringArg(args, 0)
+	i.requireWrite(path)
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		panic(i.ioError("create directory", path, err))
+	}
+
+	return Nil
+}
+
+func nativeRemove(i *Interpreter, args []Value) Value {
+	path := i.stringArg(args, 0)
+	i.requireWrite(path)
+
+	if err := os.Remove(path); err != nil {
+		panic(i.ioError("remove", path, err))
+	}
+
+	return Nil
+}
+
+func nativeOpen(i *Interpreter, args []Value) Value {
+	path := i.stringArg(args, 0)
+	i.requireRead(path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		panic(i.ioError("open", path, err))
+	}
+
+	reader := &FileReader{
+		path:   path,
+		file:   file,
+		reader: bufio.NewReader(file),
+		owner:  i.resources,
+	}
+	i.resources.add(reader)
+
+	return NewObject(reader)
+}
+
+func (i *Interpreter) ioError(action, path string, err error) *RuntimeError {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+
+	return i.nativeError(fmt.Sprintf("Can't %s '%s': %v.", action, filepath.Clean(path), err))
+}
+
+type FileReader struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	reader *bufio.Reader
+	owner  *resourceRegistry
+	closed bool
+}
+
+func (r *FileReader) String() string {
+	return fmt.Sprintf("<file %s>", r.path)
+}
+
+func (r *FileReader) Get(i *Interpreter, name Token) Value {
+	switch string(name.Lexeme()) {
+	case "readLine":
+		return method("readLine", 0, func(i *Interpreter, args []Value) Value {
+			line, _ := r.Next(i)
+			return line
+		})
+	case "close":
+		return method("close", 0, func(i *Interpreter, args []Value) Value {
+			r.close()
+			return Nil
+		})
+	}
+
+	panic(i.undefinedProperty(name))
+}
+
+func (r *FileReader) Iterator(i *Interpreter) Iterator {
+	return r
+}
+
+func (r *FileReader) Next(i *Interpreter) (Value, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return Nil, false
+	}
+
+	line, err := r.reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		panic(i.ioError("read", r.path, err))
+	}
+	if line == "" && err != nil {
+		return Nil, false
+	}
+	i.allocateString(i.callSite(), len(line))
+
+	return NewString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")), true
+}
+
+func (r *FileReader) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.file.Close()
+	r.owner.remove(r)
+}
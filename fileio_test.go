@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sandboxed(readRoots, writeRoots []string) Config {
+	return Config{Capabilities: Capabilities{ReadRoots: readRoots, WriteRoots: writeRoots}}
+}
+
+func TestFileNativesInsideRoots(t *testing.T) {
+	dir := t.TempDir()
+	config := sandboxed([]string{dir}, []string{dir})
+
+	stdout, stderr := runWithConfig(t, config, `
+var dir = "`+dir+`";
+mkdir(dir + "/sub");
+writeFile(dir + "/sub/a.txt", "one");
+appendFile(dir + "/sub/a.txt", "two");
+print readFile(dir + "/sub/a.txt");
+print exists(dir + "/sub/a.txt");
+print listDir(dir + "/sub");
+remove(dir + "/sub/a.txt");
+print exists(dir + "/sub/a.txt");
+`)
+	if stderr != "" {
+		t.Fatalf("unexpected error:\n%s", stderr)
+	}
+	if want := "onetwo\ntrue\n[a.txt]\nfalse\n"; stdout != want {
+		t.Errorf("got %q, want %q", stdout, want)
+	}
+}
+
+func TestFileNativesOutsideRoots(t *testing.T) {
+	readable, writable, outside := t.TempDir(), t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := sandboxed([]string{readable, writable}, []string{writable})
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"read outside", `readFile("` + outside + `/secret.txt");`, "Permission denied: cannot read"},
+		{"open outside", `open("` + outside + `/secret.txt");`, "Permission denied: cannot read"},
+		{"list outside", `listDir("` + outside + `");`, "Permission denied: cannot read"},
+		{"parent escape", `readFile("` + readable + `/../` + filepath.Base(outside) + `/secret.txt");`, "Permission denied: cannot read"},
+		{"write to a read-only root", `writeFile("` + readable + `/new.txt", "x");`, "Permission denied: cannot write"},
+		{"remove outside", `remove("` + outside + `/secret.txt");`, "Permission denied: cannot write"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, stderr := runWithConfig(t, config, test.source)
+			if !strings.HasPrefix(stderr, test.want) {
+				t.Errorf("got %q, want an error starting with %q", stderr, test.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(readable, "new.txt")); err == nil {
+		t.Error("writeFile created a file in a read-only root")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "secret.txt")); err != nil {
+		t.Error("remove deleted a file outside the write roots")
+	}
+}
+
+func TestSymlinkEscape(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("can't create symlinks: %v", err)
+	}
+	config := sandboxed([]string{root}, []string{root})
+
+	for _, source := range []string{
+		`readFile("` + link + `/secret.txt");`,
+		`writeFile("` + link + `/new.txt", "x");`,
+		`writeFile("` + link + `/missing/dir/new.txt", "x");`,
+	} {
+		_, stderr := runWithConfig(t, config, source)
+		if !strings.HasPrefix(stderr, "Permission denied") {
+			t.Errorf("%s: got %q, want a permission error", source, stderr)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); err == nil {
+		t.Error("writeFile followed a symlink out of the root")
+	}
+
+	resolvedOutside, err := filepath.EvalSymlinks(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := resolvePath(filepath.Join(link, "missing", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(resolvedOutside, "missing", "file"); resolved != want {
+		t.Errorf("resolvePath = %s, want %s", resolved, want)
+	}
+}
+
+func TestOpenReadLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lines.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\r\n\nfour"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := sandboxed([]string{dir}, nil)
+
+	stdout, stderr := runWithConfig(t, config, `
+var reader = open("`+path+`");
+print reader.readLine();
+for (var line in reader) print "[" + line + "]";
+print reader.readLine();
+reader.close();
+print reader.readLine();
+`)
+	if stderr != "" {
+		t.Fatalf("unexpected error:\n%s", stderr)
+	}
+	if want := "one\n[two]\n[]\n[four]\nnil\nnil\n"; stdout != want {
+		t.Errorf("got %q, want %q", stdout, want)
+	}
+}
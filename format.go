@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var numberPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+func defineConversionNatives(env *GlobalEnvironment) {
+	env.Define("str", method("str", 1, nativeStr))
+	env.Define("num", method("num", 1, nativeNum))
+	env.Define("format", method("format", VariadicArity, nativeFormat))
+}
+
+func nativeStr(i *Interpreter, args []Value) Value {
+	str := i.stringify(args[0])
+	i.allocateString(i.callSite(), len(str))
+
+	return NewString(str)
+}
+
+func nativeNum(i *Interpreter, args []Value) Value {
+	switch args[0].Kind() {
+	case NumberKind:
+		return args[0]
+	case StringKind:
+		val, _ := args[0].AsString()
+		trimmed := strings.TrimSpace(val)
+		if !numberPattern.MatchString(trimmed) {
+			return Nil
+		}
+		num, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return Nil
+		}
+		return NewNumber(num)
+	default:
+		panic(i.nativeError("Argument 1 must be a string or a number."))
+	}
+}
+
+func nativeFormat(i *Interpreter, args []Value) Value {
+	if len(args) == 0 {
+		panic(i.nativeError("Expected at least 1 argument but got 0."))
+	}
+
+	str := i.format(i.stringArg(args, 0), args[1:])
+	i.allocateString(i.callSite(), len(str))
+
+	return NewString(str)
+}
+
+func (i *Interpreter) format(layout string, args []Value) string {
+	var builder strings.Builder
+	next := 0
+
+	runes := []rune(layout)
+	for k := 0; k < len(runes); k++ {
+		if runes[k] != '%' {
+			builder.WriteRune(runes[k])
+			continue
+		}
+
+		start := k
+		k++
+		for k < len(runes) && strings.ContainsRune("-0", runes[k]) {
+			k++
+		}
+		for k < len(runes) && '0' <= runes[k] && runes[k] <= '9' {
+			k++
+		}
+		if k < len(runes) && runes[k] == '.' {
+			k++
+			for k < len(runes) && '0' <= runes[k] && runes[k] <= '9' {
+				k++
+			}
+		}
+		if k >= len(runes) {
+			panic(i.nativeError("Format string ends in the middle of a verb."))
+		}
+
+		spec, verb := string(runes[start:k]), runes[k]
+		if verb == '%' {
+			builder.WriteRune('%')
+			continue
+		}
+		if next >= len(args) {
+			panic(i.nativeError(fmt.Sprintf("Missing argument for '%s%c'.", spec, verb)))
+		}
+		builder.WriteString(i.formatVerb(spec, verb, args[next]))
+		next++
+	}
+
+	if next < len(args) {
+		panic(i.nativeError(fmt.Sprintf("Format string uses %d of %d arguments.", next, len(args))))
+	}
+
+	return builder.String()
+}
+
+func (i *Interpreter) formatVerb(spec string, verb rune, arg Value) string {
+	switch verb {
+	case 's':
+		return fmt.Sprintf(spec+"s", i.stringify(arg))
+	case 'q':
+		return fmt.Sprintf(spec+"q", i.stringify(arg))
+	case 'f', 'e':
+		return fmt.Sprintf(spec+string(verb), i.formatNumber(verb, arg))
+	case 'd', 'x':
+		num := i.formatNumber(verb, arg)
+		if num != math.Trunc(num) || math.Abs(num) >= 1<<63 {
+			panic(i.nativeError(fmt.Sprintf("'%%%c' needs an integer but got %s.", verb, i.stringify(arg))))
+		}
+		return fmt.Sprintf(spec+string(verb), int64(num))
+	default:
+		panic(i.nativeError(fmt.Sprintf("Unknown format verb '%s%c'.", spec, verb)))
+	}
+}
+
+func (i *Interpreter) formatNumber(verb rune, arg Value) float64 {
+	num, ok := arg.AsNumber()
+	if !ok {
+		panic(i.nativeError(fmt.Sprintf("'%%%c' needs a number but got %s.", verb, i.stringify(arg))))
+	}
+
+	return num
+}
@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestFormatIntegers(t *testing.T) {
+	big := `4611686018427387904 * 2`
+	tests := []scriptCase{
+		{`print format("%d", 42);`, "42\n", ""},
+		{`print format("%x", 255);`, "ff\n", ""},
+		{`print format("%d", -4611686018427387904);`, "-4611686018427387904\n", ""},
+		{`print format("%d", 1.5);`, "", "'%d' needs an integer but got 1.5."},
+		{`print format("%d", ` + big + `);`, "", "'%d' needs an integer but got 9223372036854776000."},
+		{`print format("%x", -` + big + `);`, "", "'%x' needs an integer but got -9223372036854776000."},
+		{`print format("%d", 1 / 0);`, "", "'%d' needs an integer but got inf."},
+		{`print format("%d", 0 / 0);`, "", "'%d' needs an integer but got nan."},
+	}
+
+	runScripts(t, Config{Capabilities: FullCapabilities()}, tests)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+var errGeneratorClosed = errors.New("generator closed")
+
+type generatorStep struct {
+	value      Value
+	done       bool
+	panicValue interface{}
+}
+
+type generatorState struct {
+	mu       sync.Mutex
+	function *Function
+	args     []Value
+	frame    callFrame
+	owner    *Interpreter
+	resume   chan struct{}
+	yield    chan generatorStep
+	closed   chan struct{}
+	started  bool
+	running  bool
+	finished bool
+	peeked   *generatorStep
+}
+
+type Generator struct {
+	state *generatorState
+}
+
+func NewGenerator(i *Interpreter, function *Function, args []Value) *Generator {
+	state := &generatorState{
+		function: function,
+		args:     args,
+		frame:    callFrame{callee: function, token: i.callSite()},
+		owner:    i,
+		resume:   make(chan struct{}),
+		yield:    make(chan generatorStep),
+		closed:   make(chan struct{}),
+	}
+	i.resources.add(state)
+
+	// The body goroutine only references the state, so an abandoned
+	// Generator can be collected and its goroutine released.
+	generator := &Generator{state: state}
+	runtime.SetFinalizer(generator, func(g *Generator) {
+		g.state.close()
+	})
+
+	return generator
+}
+
+func (g *Generator) String() string {
+	return fmt.Sprintf("<generator %s>", string(g.state.function.Definition.Name.Lexeme()))
+}
+
+func (g *Generator) Get(i *Interpreter, name Token) Value {
+	switch string(name.Lexeme()) {
+	case "next":
+		return method("next", 0, func(i *Interpreter, args []Value) Value {
+			val, _ := g.Next(i)
+			return val
+		})
+	case "done":
+		return method("done", 0, func(i *Interpreter, args []Value) Value {
+			return NewBool(g.state.done(i))
+		})
+	case "close":
+		return method("close", 0, func(i *Interpreter, args []Value) Value {
+			g.state.close()
+			return Nil
+		})
+	}
+
+	panic(i.undefinedProperty(name))
+}
+
+func (g *Generator) Iterator(i *Interpreter) Iterator {
+	return g
+}
+
+func (g *Generator) Next(i *Interpreter) (Value, bool) {
+	step := g.state.peek(i)
+	if !step.done {
+		g.state.mu.Lock()
+		g.state.peeked = nil
+		g.state.mu.Unlock()
+	}
+
+	return step.value, !step.done
+}
+
+func (s *generatorState) done(i *Interpreter) bool {
+	return s.peek(i).done
+}
+
+func (s *generatorState) peek(i *Interpreter) generatorStep {
+	s.mu.Lock()
+	if s.peeked != nil {
+		step := *s.peeked
+		s.mu.Unlock()
+		return step
+	}
+	if s.finished {
+		s.mu.Unlock()
+		return generatorStep{done: true}
+	}
+	if s.running {
+		s.mu.Unlock()
+		panic(i.nativeError("Generator is already running."))
+	}
+	s.running = true
+	started := s.started
+	s.started = true
+	s.mu.Unlock()
+
+	if started {
+		s.resume <- struct{}{}
+	} else {
+		go s.run(i.depth())
+	}
+	step := <-s.yield
+
+	s.mu.Lock()
+	s.running = false
+	s.finished = step.done
+	s.peeked = &generatorStep{value: step.value, done: step.done}
+	s.mu.Unlock()
+
+	if step.done {
+		s.owner.resources.remove(s)
+		if step.panicValue != nil {
+			panic(step.panicValue)
+		}
+	}
+
+	return step
+}
+
+// run runs the body on its own interpreter, which starts at the depth of
+// the interpreter that first resumed it so that recursive generators still
+// reach the call depth limit.
+func (s *generatorState) run(depth int) {
+	child := s.owner.fork()
+	child.baseDepth = depth
+	child.generator = s
+	child.callStack = append(child.callStack, s.frame)
+
+	defer func() {
+		r := recover()
+		if r == errGeneratorClosed {
+			return
+		}
+		s.yield <- generatorStep{done: true, panicValue: r}
+	}()
+
+	s.function.invokeBody(child, s.args)
+}
+
+func (s *generatorState) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished || s.running {
+		return
+	}
+	s.finished = true
+	s.peeked = nil
+	if s.started {
+		close(s.closed)
+	}
+	s.owner.resources.remove(s)
+}
+
+func (i *Interpreter) VisitYieldStmt(stmt *YieldStmt) {
+	val := Nil
+	if stmt.Value != nil {
+		val = i.evaluate(stmt.Value)
+	}
+
+	s := i.generator
+	s.yield <- generatorStep{value: val}
+	select {
+	case <-s.resume:
+	case <-s.closed:
+		panic(errGeneratorClosed)
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecursiveGeneratorOverflows(t *testing.T) {
+	_, stderr := runSource(t, `
+func rec() { for (var x in rec()) yield x; }
+for (var y in rec()) print y;
+`)
+	if !strings.HasPrefix(stderr, "Stack overflow.") {
+		t.Errorf("got %q, want a stack overflow", stderr)
+	}
+}
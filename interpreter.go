@@ -2,121 +2,180 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"sync"
 
 	"github.com/roycefanproxy/yaglox/constant"
 )
 
+const maxTraceFrames = 20
+
+type callFrame struct {
+	callee Callable
+	token  Token
+}
+
 type Interpreter struct {
-	Env *Environment
+	Globals      *GlobalEnvironment
+	Env          *Environment
+	callStack    []callFrame
+	baseDepth    int
+	maxCallDepth int
+	memory       *memoryAccount
+	capabilities Capabilities
+	stdout       io.Writer
+	stdin        *syncReader
+	reporter     *ErrorReporter
+	tasks        *sync.WaitGroup
+	channels     *channelMonitor
+	loop         *EventLoop
+	task         *asyncTask
+	spawned      bool
+	resources    *resourceRegistry
+	generator    *generatorState
+	exit         *exitStatus
+	profiler     *Profiler
+	profile      []profileFrame
 }
 
-func NewInterpreter() *Interpreter {
+func NewInterpreter(config Config) *Interpreter {
+	globals := NewGlobalEnvironment()
+	defineNatives(globals)
+	defineSystemNatives(globals, config.Args)
 
 	return &Interpreter{
-		Env: GlobalEnv,
+		Globals:      globals,
+		maxCallDepth: config.maxCallDepth(),
+		memory:       newMemoryAccount(config.MaxAllocBytes),
+		capabilities: config.Capabilities,
+		stdout:       &syncWriter{out: config.stdout()},
+		stdin:        newSyncReader(config.stdin()),
+		reporter:     NewErrorReporter(config.stderr()),
+		tasks:        &sync.WaitGroup{},
+		channels:     newChannelMonitor(),
+		loop:         NewEventLoop(config.VirtualTime),
+		resources:    newResourceRegistry(),
+		exit:         &exitStatus{},
+		profiler:     config.Profiler,
 	}
 }
 
+// Interpret resolves statements in place and runs them. Use Lox.RunProgram
+// for a program other goroutines may be running too.
 func (i *Interpreter) Interpret(statements []Stmt) {
+	defer i.tasks.Wait()
+	i.channels.reset()
+	i.channels.start()
+	defer i.channels.stop()
+	defer i.loop.shutdown()
 	defer func() {
-		recover()
+		if r := recover(); r != nil {
+			switch signal := r.(type) {
+			case *RuntimeError:
+				i.reporter.EmitRuntimeError(signal, i.stackTrace(signal.Token))
+			case *exitSignal:
+				i.requestExit(signal.code)
+			case *returnValue:
+				// Only reachable from trees the parser didn't check; a
+				// top-level return ends the script.
+			default:
+				panic(r)
+			}
+			i.callStack = i.callStack[:0]
+			i.Env = nil
+		}
 	}()
 
+	NewResolver(i.Globals).Resolve(statements)
+	if i.profiler != nil {
+		i.enterProfile(nil)
+		defer i.exitProfile()
+	}
 	for _, stmt := range statements {
 		i.execute(stmt)
 	}
+	i.loop.drain(i.reporter)
 }
 
-func (i *Interpreter) VisitLiteral(expr *Literal) interface{} {
-	return expr.Value
+func (i *Interpreter) Close() {
+	i.channels.close()
+	i.resources.closeAll()
 }
 
-func (i *Interpreter) VisitGrouping(expr *Grouping) interface{} {
+func (i *Interpreter) VisitLiteral(expr *Literal) Value {
+	return ValueOf(expr.Value)
+}
+
+func (i *Interpreter) VisitGrouping(expr *Grouping) Value {
 	return i.evaluate(expr.Expression)
 }
 
-func (i *Interpreter) VisitUnary(expr *Unary) interface{} {
+func (i *Interpreter) VisitUnary(expr *Unary) Value {
 	right := i.evaluate(expr.Right)
 
 	switch expr.Operator.Type() {
 	case constant.Minus:
 		i.checkNumberOperand(expr.Operator, right)
-		return -right.(float64)
+		return NewNumber(-right.num)
 	case constant.Bang:
-		return !i.isTruthy(right)
+		return NewBool(!i.isTruthy(right))
 	}
 
-	return nil
+	return Nil
 }
 
-func (i *Interpreter) VisitBinary(expr *Binary) interface{} {
+func (i *Interpreter) VisitBinary(expr *Binary) Value {
 	left := i.evaluate(expr.Left)
 	right := i.evaluate(expr.Right)
 
 	switch expr.Operator.Type() {
 	case constant.BangEqual:
-		return !i.isEqual(left, right)
+		return NewBool(!i.isEqual(left, right))
 	case constant.EqualEqual:
-		return i.isEqual(left, right)
+		return NewBool(i.isEqual(left, right))
 	case constant.Greater:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) > right.(float64)
+		return NewBool(left.num > right.num)
 	case constant.GreaterEqual:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) >= right.(float64)
+		return NewBool(left.num >= right.num)
 	case constant.Less:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) < right.(float64)
+		return NewBool(left.num < right.num)
 	case constant.LessEqual:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) <= right.(float64)
+		return NewBool(left.num <= right.num)
 	case constant.Minus:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) - right.(float64)
+		return NewNumber(left.num - right.num)
 	case constant.Plus:
-		lNum, isLeftNum := left.(float64)
-		rNum, isRightNum := right.(float64)
-		if isLeftNum && isRightNum {
-			return lNum + rNum
+		if left.kind == NumberKind && right.kind == NumberKind {
+			return NewNumber(left.num + right.num)
 		}
-		lStr, isLeftStr := left.(string)
-		rStr, isRightStr := right.(string)
+		lStr, isLeftStr := left.AsString()
+		rStr, isRightStr := right.AsString()
 		if isLeftStr && isRightStr {
-			return lStr + rStr
+			i.allocateString(expr.Operator, len(lStr)+len(rStr))
+			return NewString(lStr + rStr)
 		}
 		panic(i.error(expr.Operator, "Operands must be two numbers or two strings."))
 	case constant.Slash:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) / right.(float64)
+		return NewNumber(left.num / right.num)
 	case constant.Star:
 		i.checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) * right.(float64)
+		return NewNumber(left.num * right.num)
 	}
 
-	return nil
+	return Nil
 }
 
-func (i *Interpreter) VisitCall(expr *Call) interface{} {
-	callee := i.evaluate(expr.Callee)
-
-	args := []interface{}{}
-	for _, arg := range expr.Arguments {
-		args = append(args, i.evaluate(arg))
-	}
-
-	callable, ok := callee.(Callable)
-	if !ok {
-		EmitRuntimeError(expr.Operator, "Can only call functions and classes.")
-	}
-
-	if argsLen, arity := len(args), callable.Arity(); argsLen != arity {
-		msg := fmt.Sprintf("Expected %v arguments but got %v.", arity, argsLen)
-		panic(EmitRuntimeError(expr.Operator, msg))
-	}
-	return callable.Invoke(i, args)
+func (i *Interpreter) VisitCall(expr *Call) Value {
+	callable, args := i.evaluateCall(expr)
+	return i.call(callable, args, expr.Operator)
 }
 
-func (i *Interpreter) VisitLogical(expr *Logical) interface{} {
+func (i *Interpreter) VisitLogical(expr *Logical) Value {
 	left := i.evaluate(expr.Left)
 
 	if isLeftTruthy := i.isTruthy(left); expr.Operator.Type() == constant.Or {
@@ -132,13 +191,21 @@ func (i *Interpreter) VisitLogical(expr *Logical) interface{} {
 	return i.evaluate(expr.Right)
 }
 
-func (i *Interpreter) VisitVariable(expr *Variable) interface{} {
-	return i.Env.Get(expr.Name)
+func (i *Interpreter) VisitVariable(expr *Variable) Value {
+	if expr.Depth == globalDepth {
+		return i.Globals.Get(expr.Index, expr.Name)
+	}
+
+	return i.Env.GetAt(expr.Depth, expr.Index, expr.Name, i.Globals)
 }
 
-func (i *Interpreter) VisitAssign(expr *Assign) interface{} {
+func (i *Interpreter) VisitAssign(expr *Assign) Value {
 	value := i.evaluate(expr.Value)
-	i.Env.Assign(expr.Name, value)
+	if expr.Depth == globalDepth {
+		i.Globals.Assign(expr.Index, expr.Name, value)
+	} else {
+		i.Env.AssignAt(expr.Depth, expr.Index, expr.Name, value, i.Globals)
+	}
 	return value
 }
 
@@ -151,34 +218,34 @@ func (i *Interpreter) VisitFunctionStmt(stmt *FunctionStmt) {
 		Definition: stmt,
 		Closure:    i.Env,
 	}
-	i.Env.Define(string(stmt.Name.Lexeme()), function)
+	i.define(i.Env, stmt.Name, NewObject(function))
 }
 
 func (i *Interpreter) VisitReturnStmt(stmt *ReturnStmt) {
-	var val interface{}
+	val := Nil
 	if stmt.Value != nil {
 		val = i.evaluate(stmt.Value)
-		GlobalEnv.Values["return"] = val
 	}
-	panic("return")
+	panic(&returnValue{Value: val})
 }
 
 func (i *Interpreter) VisitPrintStmt(stmt *PrintStmt) {
 	val := i.evaluate(stmt.Expression)
-	fmt.Println(i.stringify(val))
+	fmt.Fprintln(i.stdout, i.stringify(val))
 }
 
 func (i *Interpreter) VisitVarDeclStmt(stmt *VarDeclStmt) {
-	var val interface{}
+	val := Nil
 
 	if stmt.Initializer != nil {
 		val = i.evaluate(stmt.Initializer)
 	}
 
-	i.Env.Define(string(stmt.Name.Lexeme()), val)
+	i.define(i.Env, stmt.Name, val)
 }
 
 func (i *Interpreter) VisitBlockStmt(stmt *BlockStmt) {
+	i.allocateBlock(stmt)
 	env := NewEnvironment(i.Env)
 	i.executeBlock(stmt.Statements, env)
 }
@@ -197,18 +264,60 @@ func (i *Interpreter) VisitWhileStmt(stmt *WhileStmt) {
 	}
 }
 
-func (i *Interpreter) evaluate(expr Expr) interface{} {
-	return expr.AcceptInterface(i)
+func (i *Interpreter) evaluateCall(expr *Call) (Callable, []Value) {
+	callee := i.evaluate(expr.Callee)
+
+	args := make([]Value, 0, len(expr.Arguments))
+	for _, arg := range expr.Arguments {
+		args = append(args, i.evaluate(arg))
+	}
+
+	callable, ok := callee.AsObject().(Callable)
+	if !ok {
+		panic(i.error(expr.Operator, "Can only call functions and classes."))
+	}
+
+	return callable, args
+}
+
+func (i *Interpreter) checkArity(callable Callable, argsLen int, token Token) {
+	if arity := callable.Arity(); arity != VariadicArity && argsLen != arity {
+		msg := fmt.Sprintf("Expected %v arguments but got %v.", arity, argsLen)
+		panic(i.error(token, msg))
+	}
+}
+
+func (i *Interpreter) call(callable Callable, args []Value, token Token) Value {
+	i.checkArity(callable, len(args), token)
+
+	if i.depth() >= i.maxCallDepth {
+		panic(i.error(token, "Stack overflow."))
+	}
+
+	i.callStack = append(i.callStack, callFrame{callee: callable, token: token})
+	val := callable.Invoke(i, args)
+	i.callStack = i.callStack[:len(i.callStack)-1]
+
+	return val
+}
+
+func (i *Interpreter) evaluate(expr Expr) Value {
+	return expr.AcceptValue(i)
 }
 
 func (i *Interpreter) execute(stmt Stmt) {
+	if i.exit.stopped() {
+		panic(i.exit.signal())
+	}
+	if i.profiler != nil {
+		i.profiler.countLine(stmt.Line())
+	}
 	stmt.Accept(i)
 }
 
 func (i *Interpreter) executeBlock(statements []Stmt, env *Environment) {
 	prevEnv := i.Env
 	defer func() {
-		recover()
 		i.Env = prevEnv
 	}()
 
@@ -218,48 +327,91 @@ func (i *Interpreter) executeBlock(statements []Stmt, env *Environment) {
 	}
 }
 
-func (i *Interpreter) isTruthy(val interface{}) bool {
-	if val == nil {
-		return false
-	}
+func (i *Interpreter) isTruthy(val Value) bool {
+	return isTruthy(val)
+}
+
+func (i *Interpreter) isEqual(left, right Value) bool {
+	return isEqual(left, right)
+}
 
-	switch v := val.(type) {
-	case bool:
-		return v
-	case float64:
-		return v != 0.0
+func isTruthy(val Value) bool {
+	switch val.kind {
+	case NilKind:
+		return false
+	case BoolKind, NumberKind:
+		return val.num != 0.0
 	default:
 		return true
 	}
 }
 
-func (i *Interpreter) isEqual(left, right interface{}) bool {
-	return left == right
+func isEqual(left, right Value) bool {
+	return left.kind == right.kind && left.num == right.num && left.ref == right.ref
 }
 
-func (i *Interpreter) checkNumberOperand(op Token, operand interface{}) {
-	_, ok := operand.(float64)
-
-	if !ok {
+func (i *Interpreter) checkNumberOperand(op Token, operand Value) {
+	if operand.kind != NumberKind {
 		panic(i.error(op, "Operand must be a number."))
 	}
 }
 
-func (i *Interpreter) checkNumberOperands(op Token, l, r interface{}) {
-	_, lOk := l.(float64)
-	_, rOk := r.(float64)
-	if !lOk || !rOk {
+func (i *Interpreter) checkNumberOperands(op Token, l, r Value) {
+	if l.kind != NumberKind || r.kind != NumberKind {
 		panic(i.error(op, "Operands must be numbers."))
 	}
 }
 
-func (*Interpreter) error(token Token, msg string) string {
-	return EmitRuntimeError(token, msg)
+func (*Interpreter) error(token Token, msg string) *RuntimeError {
+	return NewRuntimeError(token, msg)
 }
 
-func (*Interpreter) stringify(val interface{}) string {
-	if val == nil {
-		return "<nil>"
+// depth is the number of calls in progress, counting the calls that led to
+// the generator, async task or spawned call this interpreter runs.
+func (i *Interpreter) depth() int {
+	return i.baseDepth + len(i.callStack)
+}
+
+func (i *Interpreter) callSite() Token {
+	return i.callStack[len(i.callStack)-1].token
+}
+
+func (i *Interpreter) stackTrace(token Token) []string {
+	if len(i.callStack) == 0 {
+		return nil
+	}
+
+	trace := make([]string, 0, len(i.callStack)+1)
+	line := token.Line()
+	for k := len(i.callStack) - 1; k >= 0; k-- {
+		frame := i.callStack[k]
+		trace = append(trace, fmt.Sprintf("[line %d] in %s()", line, callableName(frame.callee)))
+		line = frame.token.Line()
 	}
-	return fmt.Sprintf("%v", val)
+	trace = append(trace, fmt.Sprintf("[line %d] in script", line))
+
+	if len(trace) > maxTraceFrames {
+		half := maxTraceFrames / 2
+		omitted := fmt.Sprintf("... %d more frames ...", len(trace)-maxTraceFrames)
+		trace = append(append(trace[:half:half], omitted), trace[len(trace)-half:]...)
+	}
+
+	return trace
+}
+
+func callableName(callable Callable) string {
+	switch c := callable.(type) {
+	case *Function:
+		return string(c.Definition.Name.Lexeme())
+	case *NativeFunction:
+		return c.name
+	case ClockFunction:
+		return "clock"
+	default:
+		return "native"
+	}
+}
+
+func (*Interpreter) stringify(val Value) string {
+	return Stringify(val)
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTopLevelReturn(t *testing.T) {
+	tests := []string{
+		"return;",
+		"return 1;",
+		"{ return; }",
+		"func f() {} return f;",
+	}
+
+	for _, source := range tests {
+		_, stderr := runSource(t, source)
+		if !strings.Contains(stderr, "Can't return from top-level code.") {
+			t.Errorf("%s: got %q, want a top-level return error", source, stderr)
+		}
+	}
+
+	if _, stderr := runSource(t, "func f() { func g() { return 1; } return g(); } print f();"); stderr != "" {
+		t.Errorf("return in a nested function: unexpected error %q", stderr)
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDepth int
+		omitted  string
+	}{
+		{"default depth", 0, "... 981 more frames ..."},
+		{"configured depth", 50, "... 31 more frames ..."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			lox := NewLox(Config{MaxCallDepth: test.maxDepth, Stdout: &stdout, Stderr: &stderr})
+			lox.Run("func f(n) {\n  return f(n + 1);\n}\nf(0);\n")
+
+			if code := finish(lox); code != exitRuntimeError {
+				t.Errorf("exit code %d, want %d", code, exitRuntimeError)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+			if len(lines) != 2+maxTraceFrames {
+				t.Fatalf("got %d lines, want the message and a trace of %d frames:\n%s", len(lines), maxTraceFrames+1, stderr.String())
+			}
+			if lines[0] != "Stack overflow." {
+				t.Errorf("got message %q", lines[0])
+			}
+			if lines[1] != "[line 2] in f()" {
+				t.Errorf("got innermost frame %q", lines[1])
+			}
+			if got := lines[1+maxTraceFrames/2]; got != test.omitted {
+				t.Errorf("got %q, want %q", got, test.omitted)
+			}
+			if last := lines[len(lines)-1]; last != "[line 4] in script" {
+				t.Errorf("got outermost frame %q", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+type JSONConvertible interface {
+	ToJSON(i *Interpreter) Value
+}
+
+func newJSONModule() *Module {
+	return NewModule("json", map[string]Value{
+		"parse":     method("parse", 1, nativeJSONParse),
+		"stringify": method("stringify", 2, nativeJSONStringify),
+	})
+}
+
+func nativeJSONParse(i *Interpreter, args []Value) Value {
+	decoder := json.NewDecoder(strings.NewReader(i.stringArg(args, 0)))
+	decoder.UseNumber()
+
+	val := i.decodeJSON(decoder)
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		panic(i.nativeError("Invalid JSON: unexpected data after top-level value."))
+	}
+
+	return val
+}
+
+func (i *Interpreter) decodeJSON(decoder *json.Decoder) Value {
+	token, err := decoder.Token()
+	if err != nil {
+		panic(i.nativeError(fmt.Sprintf("Invalid JSON: %v.", err)))
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			list := i.newList(i.callSite(), []Value{})
+			for decoder.More() {
+				i.allocate(i.callSite(), valueSize)
+				list.elements = append(list.elements, i.decodeJSON(decoder))
+			}
+			decoder.Token()
+			return NewObject(list)
+		case '{':
+			m := i.newMap(i.callSite())
+			for decoder.More() {
+				key, _ := i.decodeJSON(decoder).AsString()
+				i.allocate(i.callSite(), mapEntrySize+len(key))
+				m.Set(key, i.decodeJSON(decoder))
+			}
+			decoder.Token()
+			return NewObject(m)
+		}
+	case json.Number:
+		num, err := t.Float64()
+		if err != nil {
+			panic(i.nativeError(fmt.Sprintf("Invalid JSON number %s.", t)))
+		}
+		return NewNumber(num)
+	case string:
+		i.allocateString(i.callSite(), len(t))
+		return NewString(t)
+	case bool:
+		return NewBool(t)
+	case nil:
+		return Nil
+	}
+
+	panic(i.nativeError(fmt.Sprintf("Invalid JSON: unexpected %v.", token)))
+}
+
+func nativeJSONStringify(i *Interpreter, args []Value) Value {
+	indent := ""
+	switch args[1].Kind() {
+	case NilKind:
+	case StringKind:
+		indent, _ = args[1].AsString()
+	default:
+		indent = strings.Repeat(" ", i.countArg(args, 1))
+	}
+
+	var buffer bytes.Buffer
+	i.encodeJSON(&buffer, args[0], indent, 0, map[interface{}]bool{})
+	i.allocateString(i.callSite(), buffer.Len())
+
+	return NewString(buffer.String())
+}
+
+func (i *Interpreter) encodeJSON(buffer *bytes.Buffer, val Value, indent string, depth int, seen map[interface{}]bool) {
+	switch val.kind {
+	case NilKind:
+		buffer.WriteString("null")
+	case BoolKind:
+		fmt.Fprintf(buffer, "%t", val.num != 0)
+	case NumberKind:
+		if math.IsNaN(val.num) || math.IsInf(val.num, 0) {
+			panic(i.nativeError(fmt.Sprintf("Can't encode %s as JSON.", formatNumber(val.num))))
+		}
+		buffer.WriteString(formatNumber(val.num))
+	case StringKind:
+		writeJSONString(buffer, val.ref.(string))
+	default:
+		i.encodeJSONObject(buffer, val, indent, depth, seen)
+	}
+}
+
+func (i *Interpreter) encodeJSONObject(buffer *bytes.Buffer, val Value, indent string, depth int, seen map[interface{}]bool) {
+	switch v := val.ref.(type) {
+	case *List:
+		if seen[v] {
+			panic(i.nativeError("Can't encode a cyclic structure as JSON."))
+		}
+		seen[v] = true
+		elements := v.Elements()
+		buffer.WriteByte('[')
+		for k, element := range elements {
+			if k > 0 {
+				buffer.WriteByte(',')
+			}
+			writeJSONIndent(buffer, indent, depth+1)
+			i.encodeJSON(buffer, element, indent, depth+1, seen)
+		}
+		if len(elements) > 0 {
+			writeJSONIndent(buffer, indent, depth)
+		}
+		buffer.WriteByte(']')
+		delete(seen, v)
+	case *Map:
+		if seen[v] {
+			panic(i.nativeError("Can't encode a cyclic structure as JSON."))
+		}
+		seen[v] = true
+		keys := v.Keys()
+		buffer.WriteByte('{')
+		for k, key := range keys {
+			if k > 0 {
+				buffer.WriteByte(',')
+			}
+			writeJSONIndent(buffer, indent, depth+1)
+			writeJSONString(buffer, key)
+			buffer.WriteByte(':')
+			if indent != "" {
+				buffer.WriteByte(' ')
+			}
+			element, _ := v.Lookup(key)
+			i.encodeJSON(buffer, element, indent, depth+1, seen)
+		}
+		if len(keys) > 0 {
+			writeJSONIndent(buffer, indent, depth)
+		}
+		buffer.WriteByte('}')
+		delete(seen, v)
+	case JSONConvertible:
+		i.encodeJSON(buffer, v.ToJSON(i), indent, depth, seen)
+	default:
+		panic(i.nativeError(fmt.Sprintf("Can't encode %s as JSON.", Stringify(val))))
+	}
+}
+
+func writeJSONIndent(buffer *bytes.Buffer, indent string, depth int) {
+	if indent == "" {
+		return
+	}
+
+	buffer.WriteByte('\n')
+	buffer.WriteString(strings.Repeat(indent, depth))
+}
+
+func writeJSONString(buffer *bytes.Buffer, str string) {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(str)
+	buffer.Truncate(buffer.Len() - 1)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// jsonText turns text into a Lox expression for it. Lox strings have no
+// escapes, so the single quotes in text become double quotes through q,
+// which the sources below define.
+func jsonText(text string) string {
+	return `("` + strings.ReplaceAll(text, `'`, `" + q + "`) + `")`
+}
+
+const jsonPrelude = `var q = fromCodepoint(34); `
+
+func TestJSONParse(t *testing.T) {
+	tests := []scriptCase{
+		{`print json.parse("[1, 2.5, -3e2]");`, "[1, 2.5, -300]\n", ""},
+		{`print json.parse(` + jsonText(`{'a': {'b': [true, false, null]}}`) + `);`, "{a: {b: [true, false, nil]}}\n", ""},
+		{`print json.parse(` + jsonText(`{'a': {'b': [1, {'c': []}]}}`) + `).get("a").get("b").get(1).get("c").len();`, "0\n", ""},
+		{`print json.parse(` + jsonText(`{'z': 1, 'a': 2}`) + `).keys();`, "[z, a]\n", ""},
+		{`print json.parse(` + jsonText(`'café'`) + `);`, "café\n", ""},
+		{`print json.parse("  null  ");`, "nil\n", ""},
+		{`json.parse("");`, "", "Invalid JSON: EOF."},
+		{`json.parse("[1, 2");`, "", "Invalid JSON: "},
+		{`json.parse(` + jsonText(`{'a' 1}`) + `);`, "", "Invalid JSON: "},
+		{`json.parse("[1] [2]");`, "", "Invalid JSON: unexpected data after top-level value."},
+		{`json.parse("nul");`, "", "Invalid JSON: "},
+		{`json.parse("1e999");`, "", "Invalid JSON number 1e999."},
+		{`json.parse(1);`, "", "Argument 1 must be a string."},
+	}
+	for k := range tests {
+		tests[k].source = jsonPrelude + tests[k].source
+	}
+
+	runScripts(t, Config{}, tests)
+}
+
+func TestJSONStringify(t *testing.T) {
+	const nested = `var m = map(); m.set("name", "x"); m.set("list", list(1, list(), map())); `
+	tests := []scriptCase{
+		{`print json.stringify(nil, nil);`, "null\n", ""},
+		{`print json.stringify(list(true, 1.5, "a" + q + "b"), nil);`, `[true,1.5,"a\"b"]` + "\n", ""},
+		{nested + `print json.stringify(m, nil);`, `{"name":"x","list":[1,[],{}]}` + "\n", ""},
+		{nested + `print json.stringify(m, 2);`, "{\n  \"name\": \"x\",\n  \"list\": [\n    1,\n    [],\n    {}\n  ]\n}\n", ""},
+		{nested + `print json.stringify(m, fromCodepoint(9));`, "{\n\t\"name\": \"x\",\n\t\"list\": [\n\t\t1,\n\t\t[],\n\t\t{}\n\t]\n}\n", ""},
+		{nested + `print json.stringify(m, 0) == json.stringify(m, nil);`, "true\n", ""},
+		{`var l = list(1); l.push(l); json.stringify(l, nil);`, "", "Can't encode a cyclic structure as JSON."},
+		{`var m = map(); m.set("self", list(m)); json.stringify(m, nil);`, "", "Can't encode a cyclic structure as JSON."},
+		{`var a = list(); print json.stringify(list(a, a), nil);`, "[[],[]]\n", ""},
+		{`func f() {} json.stringify(f, nil);`, "", "Can't encode <fn f> as JSON."},
+		{`json.stringify(list(clock), nil);`, "", "Can't encode <native fn> as JSON."},
+		{`json.stringify(channel(0), nil);`, "", "Can't encode "},
+		{`json.stringify(1 / 0, nil);`, "", "Can't encode inf as JSON."},
+		{`json.stringify(0 / 0, nil);`, "", "Can't encode nan as JSON."},
+		{
+			`var text = ` + jsonText(`{'a': [1, {'b': null}]}`) + `; print json.stringify(json.parse(json.stringify(json.parse(text), 2)), nil) == text.replace(" ", "");`,
+			"true\n", "",
+		},
+	}
+	for k := range tests {
+		tests[k].source = jsonPrelude + tests[k].source
+	}
+
+	runScripts(t, Config{}, tests)
+}
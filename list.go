@@ -0,0 +1,125 @@
+package main
+
+import "sync"
+
+const (
+	listHeaderSize = 48
+	valueSize      = 32
+)
+
+type List struct {
+	mu       sync.RWMutex
+	elements []Value
+}
+
+func NewList(elements []Value) *List {
+	return &List{
+		elements: elements,
+	}
+}
+
+func (i *Interpreter) newList(token Token, elements []Value) *List {
+	i.allocate(token, listHeaderSize+len(elements)*valueSize)
+	return NewList(elements)
+}
+
+func nativeList(i *Interpreter, args []Value) Value {
+	return NewObject(i.newList(i.callSite(), append([]Value{}, args...)))
+}
+
+func (l *List) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.elements)
+}
+
+func (l *List) Elements() []Value {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]Value{}, l.elements...)
+}
+
+func (l *List) Get(i *Interpreter, name Token) Value {
+	switch string(name.Lexeme()) {
+	case "len":
+		return method("len", 0, func(i *Interpreter, args []Value) Value {
+			return NewNumber(float64(l.Len()))
+		})
+	case "get":
+		return method("get", 1, func(i *Interpreter, args []Value) Value {
+			l.mu.RLock()
+			defer l.mu.RUnlock()
+
+			return l.elements[i.indexArg(args, 0, len(l.elements)-1)]
+		})
+	case "set":
+		return method("set", 2, func(i *Interpreter, args []Value) Value {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.elements[i.indexArg(args, 0, len(l.elements)-1)] = args[1]
+			return args[1]
+		})
+	case "push":
+		return method("push", 1, func(i *Interpreter, args []Value) Value {
+			i.allocate(i.callSite(), valueSize)
+
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.elements = append(l.elements, args[0])
+			return NewNumber(float64(len(l.elements)))
+		})
+	case "pop":
+		return method("pop", 0, func(i *Interpreter, args []Value) Value {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			if len(l.elements) == 0 {
+				panic(i.nativeError("Can't pop from an empty list."))
+			}
+			last := l.elements[len(l.elements)-1]
+			l.elements = l.elements[:len(l.elements)-1]
+			return last
+		})
+	}
+
+	panic(i.undefinedProperty(name))
+}
+
+func (l *List) Iterator(i *Interpreter) Iterator {
+	return &listIterator{list: l}
+}
+
+func (l *List) String() string {
+	return Stringify(NewObject(l))
+}
+
+type listIterator struct {
+	list  *List
+	index int
+}
+
+func (it *listIterator) Next(i *Interpreter) (Value, bool) {
+	it.list.mu.RLock()
+	defer it.list.mu.RUnlock()
+
+	if it.index >= len(it.list.elements) {
+		return Nil, false
+	}
+	val := it.list.elements[it.index]
+	it.index++
+
+	return val, true
+}
+
+func toValues(strs []string) []Value {
+	values := make([]Value, 0, len(strs))
+	for _, str := range strs {
+		values = append(values, NewString(str))
+	}
+
+	return values
+}
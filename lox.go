@@ -0,0 +1,101 @@
+package main
+
+type Lox struct {
+	interpreter *Interpreter
+	reporter    *ErrorReporter
+	optimize    bool
+}
+
+func NewLox(config Config) *Lox {
+	interpreter := NewInterpreter(config)
+
+	return &Lox{
+		interpreter: interpreter,
+		reporter:    interpreter.reporter,
+		optimize:    config.Optimize,
+	}
+}
+
+func (l *Lox) Tokenize(source string) []Token {
+	return NewTokenizer(source, l.reporter).Parse()
+}
+
+func (l *Lox) Parse(source string) []Stmt {
+	tokens := l.Tokenize(source)
+	statements := NewParser(tokens, l.reporter).Parse()
+
+	if l.reporter.HasError() {
+		return nil
+	}
+
+	return statements
+}
+
+func (l *Lox) Check(source string) bool {
+	statements := l.Parse(source)
+	if statements == nil {
+		return false
+	}
+
+	NewResolver(l.interpreter.Globals).Resolve(statements)
+	return true
+}
+
+func (l *Lox) Run(source string) {
+	statements := l.Parse(source)
+	if statements == nil {
+		return
+	}
+
+	l.run(statements)
+}
+
+// RunProgram runs a program that may be shared with other interpreters.
+// Resolving and optimizing write to the tree, so it runs a copy and leaves
+// statements untouched.
+func (l *Lox) RunProgram(statements []Stmt) {
+	l.run(cloneStmts(statements))
+}
+
+func (l *Lox) run(statements []Stmt) {
+	if l.optimize {
+		statements = Optimize(statements)
+	}
+
+	l.interpreter.Interpret(statements)
+}
+
+func (l *Lox) RunPrompt(source string) {
+	statements := l.Parse(source)
+	if statements == nil {
+		return
+	}
+
+	if len(statements) == 1 {
+		if stmt, ok := statements[0].(*ExprStmt); ok {
+			statements[0] = &PrintStmt{Expression: stmt.Expression}
+		}
+	}
+
+	l.run(statements)
+}
+
+func (l *Lox) Close() {
+	l.interpreter.Close()
+}
+
+func (l *Lox) ExitCode() (int, bool) {
+	return l.interpreter.ExitCode()
+}
+
+func (l *Lox) HasError() bool {
+	return l.reporter.HasError()
+}
+
+func (l *Lox) HasRuntimeError() bool {
+	return l.reporter.HasRuntimeError()
+}
+
+func (l *Lox) ResetErrors() {
+	l.reporter.Reset()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runSource runs source on a fresh interpreter with every capability and
+// returns what it printed to stdout and stderr.
+func runSource(tb testing.TB, source string) (string, string) {
+	tb.Helper()
+
+	return runWithConfig(tb, Config{Capabilities: FullCapabilities()}, source)
+}
+
+// runWithConfig is runSource with a given configuration. Stdout and Stderr
+// are replaced and time is virtual.
+func runWithConfig(tb testing.TB, config Config, source string) (string, string) {
+	tb.Helper()
+
+	var stdout, stderr bytes.Buffer
+	config.Stdout = &stdout
+	config.Stderr = &stderr
+	config.VirtualTime = true
+
+	lox := NewLox(config)
+	lox.Run(source)
+	lox.Close()
+
+	return stdout.String(), stderr.String()
+}
+
+// scriptCase is a script, what it should print, and the message its error
+// output should start with, or "" when it should run without errors.
+type scriptCase struct {
+	source string
+	stdout string
+	err    string
+}
+
+// runScripts runs each case with config and reports every case whose output
+// differs from what it expects.
+func runScripts(t *testing.T, config Config, tests []scriptCase) {
+	t.Helper()
+
+	for _, test := range tests {
+		stdout, stderr := runWithConfig(t, config, test.source)
+		if stdout != test.stdout || !strings.HasPrefix(stderr, test.err) || (test.err == "") != (stderr == "") {
+			t.Errorf("%s: got %q and error %q, want %q and error %q", test.source, stdout, stderr, test.stdout, test.err)
+		}
+	}
+}
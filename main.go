@@ -2,86 +2,332 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
+const (
+	exitOK           = 0
+	exitUsage        = 64
+	exitCompileError = 65
+	exitNoInput      = 66
+	exitRuntimeError = 70
+	exitCantCreate   = 73
+)
+
+const usage = `Usage:
+  lox [script [args...]]        run a script, or start a REPL without one
+  lox run <script|-> [args...]  run a script, reading it from stdin for "-"
+      -json                     run a JSON syntax tree printed by "ast -json"
+      -O                        fold constants and drop dead code first
+      -profile <file>           write a pprof profile to file and a report
+                                to stderr
+  lox repl                      start an interactive prompt
+  lox eval -e <code> [args...]  run code given on the command line
+      -O                        fold constants and drop dead code first
+  lox check <script|->          tokenize, parse and resolve without running
+  lox tokens <script|->         print the tokens of a script
+  lox ast <script|->            print the syntax tree of a script
+      -json                     print the tree as JSON instead
+      -O                        print the optimized tree
+  lox bench <script|->          run a script repeatedly and report its cost
+      -n <runs>                 number of runs (default 10)
+      -O                        fold constants and drop dead code first
+`
+
+type command func(args []string) int
+
 func main() {
-	switch len(os.Args) {
-	case 2:
-		executeFile(os.Args[1])
-	case 1:
-		executePrompt()
-	default:
-		fmt.Print("Usage: lox [script]")
-		os.Exit(64)
-	}
-
-}
-
-// func main() {
-// 	expression := &Binary{
-// 		Left: &Unary{
-// 			Operator: NewToken(Minus, []rune("-"), nil, 1),
-// 			Right:    &Literal{Value: 123},
-// 		},
-// 		Operator: NewToken(Star, []rune("*"), nil, 1),
-// 		Right: &Grouping{
-// 			Expression: &Literal{Value: 45.67},
-// 		},
-// 	}
-// 	fmt.Println(ASTPrinter{}.Print(expression))
-// }
-
-func execute(source string) {
-	tokenizer := NewTokenizer(source)
-	tokens := tokenizer.Parse()
-	parser := NewParser(tokens)
-	statements := parser.Parse()
-
-	if HasError {
-		return
-	}
-
-	/*
-		fmt.Println("tokens: ")
-		for _, t := range tokens {
-			fmt.Printf("%s ", t)
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+func runCLI(args []string) int {
+	commands := map[string]command{
+		"run":    runCommand,
+		"repl":   replCommand,
+		"eval":   evalCommand,
+		"check":  checkCommand,
+		"tokens": tokensCommand,
+		"ast":    astCommand,
+		"bench":  benchCommand,
+	}
+
+	if len(args) == 0 {
+		return replCommand(args)
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return exitOK
+	}
+
+	if cmd, ok := commands[args[0]]; ok {
+		return cmd(args[1:])
+	}
+
+	// Anything else, including run's flags, is a bare "lox [flags] script".
+	return runCommand(args)
+}
+
+func usageError(msg string) int {
+	fmt.Fprintf(os.Stderr, "lox: %s\n\n%s", msg, usage)
+	return exitUsage
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+func newCLILox(args []string, optimize bool) *Lox {
+	return NewLox(cliConfig(args, optimize))
+}
+
+func cliConfig(args []string, optimize bool) Config {
+	return Config{
+		Capabilities: FullCapabilities(),
+		Args:         args,
+		Optimize:     optimize,
+	}
+}
+
+func readSource(path string) (string, bool) {
+	var bin []byte
+	var err error
+	if path == "-" {
+		bin, err = io.ReadAll(os.Stdin)
+	} else {
+		bin, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lox: %v\n", err)
+		return "", false
+	}
+
+	return string(bin), true
+}
+
+func runCommand(args []string) int {
+	flags := newFlagSet("run")
+	fromJSON := flags.Bool("json", false, "run a JSON syntax tree")
+	optimize := flags.Bool("O", false, "optimize before running")
+	profile := flags.String("profile", "", "write a pprof profile to this file")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageError("run needs a script")
+	}
+
+	source, ok := readSource(args[0])
+	if !ok {
+		return exitNoInput
+	}
+
+	config := cliConfig(args[1:], *optimize)
+	if *profile != "" {
+		config.Profiler = NewProfiler(args[0])
+	}
+
+	var code int
+	if *fromJSON {
+		statements, err := UnmarshalProgram([]byte(source))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lox: %v\n", err)
+			return exitCompileError
 		}
-		fmt.Println()
-		fmt.Println(ASTPrinter{}.Print(expression))
-	*/
+		lox := NewLox(config)
+		lox.RunProgram(statements)
+		code = finish(lox)
+	} else {
+		code = executeSource(source, config)
+	}
+
+	if config.Profiler != nil && !writeProfile(config.Profiler, *profile) {
+		return exitCantCreate
+	}
 
-	NewInterpreter().Interpret(statements)
+	return code
 }
 
-func executeFile(filePath string) {
-	bin, err := os.ReadFile(filePath)
+func writeProfile(profiler *Profiler, path string) bool {
+	if err := profiler.WriteReport(os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "lox: %v\n", err)
+		return false
+	}
+
+	out, err := os.Create(path)
+	if err == nil {
+		err = profiler.WritePprof(out)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
-		panic(err.Error())
+		fmt.Fprintf(os.Stderr, "lox: %v\n", err)
+		return false
+	}
+
+	return true
+}
+
+func evalCommand(args []string) int {
+	flags := newFlagSet("eval")
+	code := flags.String("e", "", "code to run")
+	optimize := flags.Bool("O", false, "optimize before running")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
 	}
+	if *code == "" {
+		return usageError("eval needs -e <code>")
+	}
+
+	return executeSource(*code, cliConfig(flags.Args(), *optimize))
+}
+
+func executeSource(source string, config Config) int {
+	lox := NewLox(config)
+	lox.Run(source)
+	return finish(lox)
+}
 
-	execute(string(bin))
+func finish(lox *Lox) int {
+	lox.Close()
 
-	if HasError {
-		os.Exit(65)
+	if lox.HasError() {
+		return exitCompileError
 	}
-	if HasRuntimeError {
-		os.Exit(70)
+	if code, ok := lox.ExitCode(); ok {
+		return code
 	}
+	if lox.HasRuntimeError() {
+		return exitRuntimeError
+	}
+
+	return exitOK
+}
+
+func replCommand(args []string) int {
+	if len(args) > 0 {
+		return usageError("repl takes no arguments")
+	}
+
+	return repl(os.Stdin, os.Stdout, cliConfig(nil, false))
 }
 
-func executePrompt() {
-	reader := bufio.NewScanner(os.Stdin)
+// repl reads prompt lines from in through the same buffered reader the
+// interpreter's readLine, input and readAll use, so that a line a script
+// reads is not also run as a prompt. bufio.NewReader returns a
+// *bufio.Reader it is given as is, which is what makes the two share it.
+func repl(in io.Reader, out io.Writer, config Config) int {
+	reader := bufio.NewReader(in)
+	config.Stdin = reader
+	config.Stdout = out
+	lox := NewLox(config)
+	defer lox.Close()
 
 	for {
-		fmt.Print("->")
-		if !reader.Scan() {
+		fmt.Fprint(out, "->")
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
 			break
 		}
 
-		execute(reader.Text())
-		HasError = false
+		lox.RunPrompt(strings.TrimRight(line, "\r\n"))
+		lox.ResetErrors()
+
+		if code, ok := lox.ExitCode(); ok {
+			return code
+		}
+	}
+
+	return exitOK
+}
+
+func checkCommand(args []string) int {
+	source, code := readSingleSource("check", args)
+	if code != exitOK {
+		return code
+	}
+
+	if !newCLILox(nil, false).Check(source) {
+		return exitCompileError
+	}
+
+	return exitOK
+}
+
+func tokensCommand(args []string) int {
+	source, code := readSingleSource("tokens", args)
+	if code != exitOK {
+		return code
+	}
+
+	lox := newCLILox(nil, false)
+	for _, token := range lox.Tokenize(source) {
+		fmt.Printf("%d %s\n", token.Line(), token)
+	}
+
+	if lox.HasError() {
+		return exitCompileError
+	}
+
+	return exitOK
+}
+
+func astCommand(args []string) int {
+	flags := newFlagSet("ast")
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	optimize := flags.Bool("O", false, "print the optimized tree")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	source, code := readSingleSource("ast", flags.Args())
+	if code != exitOK {
+		return code
+	}
+
+	statements := newCLILox(nil, false).Parse(source)
+	if statements == nil {
+		return exitCompileError
+	}
+	if *optimize {
+		statements = Optimize(statements)
+	}
+
+	if !*asJSON {
+		fmt.Print(ASTPrinter{}.PrintProgram(statements))
+		return exitOK
+	}
+
+	data, err := MarshalProgram(statements)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lox: %v\n", err)
+		return exitCompileError
+	}
+	fmt.Println(string(data))
+
+	return exitOK
+}
+
+func readSingleSource(name string, args []string) (string, int) {
+	if len(args) != 1 {
+		return "", usageError(fmt.Sprintf("%s needs exactly one script", name))
+	}
+
+	source, ok := readSource(args[0])
+	if !ok {
+		return "", exitNoInput
 	}
 
+	return source, exitOK
 }
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestREPLSharesStdin(t *testing.T) {
+	in := strings.NewReader("print readLine();\nhello\nprint input(\"? \");\nworld\r\nprint 1 + 2;")
+	var out strings.Builder
+
+	if code := repl(in, &out, Config{Capabilities: FullCapabilities()}); code != exitOK {
+		t.Fatalf("repl exited with %d", code)
+	}
+	if want := "->hello\n->? world\n->3\n->"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import "sync"
+
+const mapEntrySize = 48
+
+type Map struct {
+	mu     sync.RWMutex
+	keys   []string
+	values map[string]Value
+}
+
+func NewMap() *Map {
+	return &Map{
+		values: map[string]Value{},
+	}
+}
+
+func (i *Interpreter) newMap(token Token) *Map {
+	i.allocate(token, listHeaderSize)
+	return NewMap()
+}
+
+func nativeMap(i *Interpreter, args []Value) Value {
+	return NewObject(i.newMap(i.callSite()))
+}
+
+func (m *Map) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.keys)
+}
+
+func (m *Map) Keys() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string{}, m.keys...)
+}
+
+func (m *Map) Lookup(key string) (Value, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	val, ok := m.values[key]
+	return val, ok
+}
+
+func (m *Map) Set(key string, value Value) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *Map) Remove(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	for k, existing := range m.keys {
+		if existing == key {
+			m.keys = append(m.keys[:k], m.keys[k+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+func (m *Map) Get(i *Interpreter, name Token) Value {
+	switch string(name.Lexeme()) {
+	case "len":
+		return method("len", 0, func(i *Interpreter, args []Value) Value {
+			return NewNumber(float64(m.Len()))
+		})
+	case "get":
+		return method("get", 1, func(i *Interpreter, args []Value) Value {
+			val, _ := m.Lookup(i.stringArg(args, 0))
+			return val
+		})
+	case "set":
+		return method("set", 2, func(i *Interpreter, args []Value) Value {
+			key := i.stringArg(args, 0)
+			if _, ok := m.Lookup(key); !ok {
+				i.allocate(i.callSite(), mapEntrySize+len(key))
+			}
+			m.Set(key, args[1])
+			return args[1]
+		})
+	case "has":
+		return method("has", 1, func(i *Interpreter, args []Value) Value {
+			_, ok := m.Lookup(i.stringArg(args, 0))
+			return NewBool(ok)
+		})
+	case "remove":
+		return method("remove", 1, func(i *Interpreter, args []Value) Value {
+			return NewBool(m.Remove(i.stringArg(args, 0)))
+		})
+	case "keys":
+		return method("keys", 0, func(i *Interpreter, args []Value) Value {
+			return NewObject(i.newList(i.callSite(), toValues(m.Keys())))
+		})
+	}
+
+	panic(i.undefinedProperty(name))
+}
+
+func (m *Map) Iterator(i *Interpreter) Iterator {
+	return &listIterator{list: NewList(toValues(m.Keys()))}
+}
+
+func (m *Map) String() string {
+	return Stringify(NewObject(m))
+}
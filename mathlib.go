@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+const maxSafeInteger = 1<<53 - 1
+
+func newMathModule() *Module {
+	return NewModule("math", map[string]Value{
+		"pi":  NewNumber(math.Pi),
+		"e":   NewNumber(math.E),
+		"inf": NewNumber(math.Inf(1)),
+		"nan": NewNumber(math.NaN()),
+
+		"sqrt":  mathFunction1("sqrt", math.Sqrt),
+		"abs":   mathFunction1("abs", math.Abs),
+		"floor": mathFunction1("floor", math.Floor),
+		"ceil":  mathFunction1("ceil", math.Ceil),
+		"round": mathFunction1("round", math.Round),
+		"trunc": mathFunction1("trunc", math.Trunc),
+		"sin":   mathFunction1("sin", math.Sin),
+		"cos":   mathFunction1("cos", math.Cos),
+		"tan":   mathFunction1("tan", math.Tan),
+		"log":   mathFunction1("log", math.Log),
+		"exp":   mathFunction1("exp", math.Exp),
+		"pow":   mathFunction2("pow", math.Pow),
+		"atan2": mathFunction2("atan2", math.Atan2),
+		"min":   mathFunction2("min", math.Min),
+		"max":   mathFunction2("max", math.Max),
+
+		"isInteger": mathPredicate("isInteger", func(x float64) bool {
+			return x == math.Trunc(x) && !math.IsInf(x, 0)
+		}),
+		"isSafeInteger": mathPredicate("isSafeInteger", func(x float64) bool {
+			return x == math.Trunc(x) && math.Abs(x) <= maxSafeInteger
+		}),
+		"isNaN": mathPredicate("isNaN", math.IsNaN),
+		"isFinite": mathPredicate("isFinite", func(x float64) bool {
+			return !math.IsInf(x, 0) && !math.IsNaN(x)
+		}),
+
+		"random": method("random", 0, func(i *Interpreter, args []Value) Value {
+			i.requireCapability(i.capabilities.Random, "random")
+			return NewNumber(rand.Float64())
+		}),
+	})
+}
+
+func mathFunction1(name string, fn func(float64) float64) Value {
+	return method(name, 1, func(i *Interpreter, args []Value) Value {
+		return NewNumber(fn(i.numberArg(args, 0)))
+	})
+}
+
+func mathFunction2(name string, fn func(float64, float64) float64) Value {
+	return method(name, 2, func(i *Interpreter, args []Value) Value {
+		return NewNumber(fn(i.numberArg(args, 0), i.numberArg(args, 1)))
+	})
+}
+
+func mathPredicate(name string, fn func(float64) bool) Value {
+	return method(name, 1, func(i *Interpreter, args []Value) Value {
+		return NewBool(fn(i.numberArg(args, 0)))
+	})
+}
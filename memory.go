@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/roycefanproxy/yaglox/constant"
+)
+
+const (
+	stringHeaderSize = 16
+	envBaseSize      = 64
+	envEntrySize     = 48
+)
+
+// memoryAccount counts the estimated bytes an interpreter has allocated.
+// The count only grows; it doesn't track what is still live.
+type memoryAccount struct {
+	allocated int64
+	limit     int64
+}
+
+func newMemoryAccount(limit int64) *memoryAccount {
+	return &memoryAccount{
+		limit: limit,
+	}
+}
+
+// AllocatedBytes returns the estimated bytes allocated so far, which is what
+// Config.MaxAllocBytes is compared against.
+func (i *Interpreter) AllocatedBytes() int64 {
+	return atomic.LoadInt64(&i.memory.allocated)
+}
+
+func (i *Interpreter) allocate(token Token, size int) {
+	allocated := atomic.AddInt64(&i.memory.allocated, int64(size))
+
+	if limit := i.memory.limit; limit > 0 && allocated > limit {
+		msg := fmt.Sprintf("Memory limit of %d bytes exceeded.", limit)
+		panic(i.error(token, msg))
+	}
+}
+
+func (i *Interpreter) allocateString(token Token, length int) {
+	i.allocate(token, stringHeaderSize+length)
+}
+
+func (i *Interpreter) allocateEnvironment(token Token, entries int) {
+	i.allocate(token, envBaseSize+entries*envEntrySize)
+}
+
+// allocateBlock charges the environment of a block. Blocks the parser
+// didn't produce, such as the ones the optimizer leaves behind, may have no
+// brace to report an error at, so they get one on the block's line.
+func (i *Interpreter) allocateBlock(stmt *BlockStmt) {
+	brace := stmt.Brace
+	if brace == nil {
+		brace = NewToken(constant.LeftBrace, []rune("{"), nil, stmt.Line())
+	}
+
+	i.allocateEnvironment(brace, 0)
+}
+
+func (i *Interpreter) define(env *Environment, name Token, value Value) {
+	i.allocate(name, envEntrySize+len(name.Lexeme()))
+	if env == nil {
+		i.Globals.Define(string(name.Lexeme()), value)
+		return
+	}
+
+	env.Define(string(name.Lexeme()), value)
+}
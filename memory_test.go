@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func allocatedBytes(tb testing.TB, source string) int64 {
+	tb.Helper()
+
+	lox := NewLox(Config{Capabilities: FullCapabilities(), Stdout: io.Discard})
+	lox.Run(source)
+	lox.Close()
+	if lox.HasError() || lox.HasRuntimeError() {
+		tb.Fatalf("%q failed", source)
+	}
+
+	return lox.interpreter.AllocatedBytes()
+}
+
+func TestEnvironmentsAreCharged(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   int64
+	}{
+		{"block", "", "{}", envBaseSize},
+		{"call", "func f(a, b) {}", "func f(a, b) {} f(1, 2);", envBaseSize + 2*envEntrySize},
+		{"for-in", "var l = list(1);", "var l = list(1); for (var x in l) {}", 2*envBaseSize + envEntrySize},
+		{"select default", "", "select { default {} }", envBaseSize},
+		{
+			"select case",
+			"var c = channel(1); send(c, 1);",
+			"var c = channel(1); send(c, 1); select { case var v = receive(c) {} }",
+			envBaseSize + envEntrySize,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := allocatedBytes(t, test.after) - allocatedBytes(t, test.before)
+			if got != test.want {
+				t.Errorf("charged %d bytes, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMaxAllocBytesIsCumulative(t *testing.T) {
+	var stderr bytes.Buffer
+	lox := NewLox(Config{MaxAllocBytes: 100 * envBaseSize, Stdout: io.Discard, Stderr: &stderr})
+	lox.Run("for (var k = 0; k < 100; k = k + 1) {}")
+	lox.Close()
+
+	if !lox.HasRuntimeError() {
+		t.Error("100 blocks fit in a budget of 100 empty environments")
+	}
+}
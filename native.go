@@ -0,0 +1,95 @@
+package main
+
+import "fmt"
+
+type NativeFunction struct {
+	name  string
+	arity int
+	fn    func(i *Interpreter, args []Value) Value
+}
+
+func NewNativeFunction(name string, arity int, fn func(i *Interpreter, args []Value) Value) *NativeFunction {
+	return &NativeFunction{
+		name:  name,
+		arity: arity,
+		fn:    fn,
+	}
+}
+
+func method(name string, arity int, fn func(i *Interpreter, args []Value) Value) Value {
+	return NewObject(NewNativeFunction(name, arity, fn))
+}
+
+func (n *NativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *NativeFunction) Invoke(i *Interpreter, args []Value) Value {
+	return n.fn(i, args)
+}
+
+func (n *NativeFunction) String() string {
+	return Stringify(NewObject(n))
+}
+
+func defineNatives(env *GlobalEnvironment) {
+	env.Define("clock", NewObject(ClockFunction{}))
+	defineConcurrencyNatives(env)
+	defineAsyncNatives(env)
+	defineConversionNatives(env)
+	defineFileNatives(env)
+	env.Define("json", NewObject(newJSONModule()))
+	env.Define("list", NewObject(NewNativeFunction("list", VariadicArity, nativeList)))
+	env.Define("map", NewObject(NewNativeFunction("map", 0, nativeMap)))
+	env.Define("math", NewObject(newMathModule()))
+	env.Define("fromCodepoint", NewObject(NewNativeFunction("fromCodepoint", 1, nativeFromCodepoint)))
+}
+
+func (i *Interpreter) nativeError(msg string) *RuntimeError {
+	return i.error(i.callSite(), msg)
+}
+
+func (i *Interpreter) numberArg(args []Value, index int) float64 {
+	num, ok := args[index].AsNumber()
+	if !ok {
+		panic(i.nativeError(fmt.Sprintf("Argument %d must be a number.", index+1)))
+	}
+
+	return num
+}
+
+func (i *Interpreter) stringArg(args []Value, index int) string {
+	str, ok := args[index].AsString()
+	if !ok {
+		panic(i.nativeError(fmt.Sprintf("Argument %d must be a string.", index+1)))
+	}
+
+	return str
+}
+
+func (i *Interpreter) listArg(args []Value, index int) *List {
+	list, ok := args[index].AsObject().(*List)
+	if !ok {
+		panic(i.nativeError(fmt.Sprintf("Argument %d must be a list.", index+1)))
+	}
+
+	return list
+}
+
+func (i *Interpreter) countArg(args []Value, index int) int {
+	num := i.numberArg(args, index)
+	if num < 0 || num != float64(int(num)) {
+		panic(i.nativeError(fmt.Sprintf("Argument %d must be a non-negative integer.", index+1)))
+	}
+
+	return int(num)
+}
+
+func (i *Interpreter) indexArg(args []Value, index int, max int) int {
+	num := i.countArg(args, index)
+	if num > max {
+		panic(i.nativeError(fmt.Sprintf("Argument %d is out of range.", index+1)))
+	}
+
+	return num
+}
@@ -0,0 +1,927 @@
+// Code generated by "genast -helpers=walk,pos,clone,transform"; DO NOT EDIT.
+
+package main
+
+import "fmt"
+
+type Node interface {
+	Children() []Node
+	Line() int
+	TransformChildren(fn func(Node) Node)
+}
+
+func transformMismatch(node Node, want string) string {
+	return fmt.Sprintf("transform returned %T where %s is needed", node, want)
+}
+
+func cloneExpr(expr Expr) Expr {
+	if expr == nil {
+		return nil
+	}
+	return expr.Clone()
+}
+
+func cloneExprs(list []Expr) []Expr {
+	if list == nil {
+		return nil
+	}
+	cloned := make([]Expr, len(list))
+	for k, item := range list {
+		cloned[k] = cloneExpr(item)
+	}
+	return cloned
+}
+
+func toExpr(node Node) Expr {
+	if node == nil {
+		return nil
+	}
+	expr, ok := node.(Expr)
+	if !ok {
+		panic(transformMismatch(node, "Expr"))
+	}
+	return expr
+}
+
+func transformExprs(list []Expr, fn func(Node) Node) []Expr {
+	kept := list[:0]
+	for _, item := range list {
+		if item = toExpr(fn(item)); item != nil {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func (e *Assign) Children() []Node {
+	var children []Node
+	if e.Value != nil {
+		children = append(children, e.Value)
+	}
+	return children
+}
+
+func (e *Assign) Line() int {
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	if e.Value != nil {
+		if line := e.Value.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Assign) Clone() Expr {
+	c := *e
+	c.Value = cloneExpr(e.Value)
+	return &c
+}
+
+func (e *Assign) TransformChildren(fn func(Node) Node) {
+	if e.Value != nil {
+		e.Value = toExpr(fn(e.Value))
+	}
+}
+
+func (e *Await) Children() []Node {
+	var children []Node
+	if e.Value != nil {
+		children = append(children, e.Value)
+	}
+	return children
+}
+
+func (e *Await) Line() int {
+	if e.Keyword != nil {
+		return e.Keyword.Line()
+	}
+	if e.Value != nil {
+		if line := e.Value.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Await) Clone() Expr {
+	c := *e
+	c.Value = cloneExpr(e.Value)
+	return &c
+}
+
+func (e *Await) TransformChildren(fn func(Node) Node) {
+	if e.Value != nil {
+		e.Value = toExpr(fn(e.Value))
+	}
+}
+
+func (e *Binary) Children() []Node {
+	var children []Node
+	if e.Left != nil {
+		children = append(children, e.Left)
+	}
+	if e.Right != nil {
+		children = append(children, e.Right)
+	}
+	return children
+}
+
+func (e *Binary) Line() int {
+	if e.Left != nil {
+		if line := e.Left.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Operator != nil {
+		return e.Operator.Line()
+	}
+	if e.Right != nil {
+		if line := e.Right.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Binary) Clone() Expr {
+	c := *e
+	c.Left = cloneExpr(e.Left)
+	c.Right = cloneExpr(e.Right)
+	return &c
+}
+
+func (e *Binary) TransformChildren(fn func(Node) Node) {
+	if e.Left != nil {
+		e.Left = toExpr(fn(e.Left))
+	}
+	if e.Right != nil {
+		e.Right = toExpr(fn(e.Right))
+	}
+}
+
+func (e *Call) Children() []Node {
+	var children []Node
+	if e.Callee != nil {
+		children = append(children, e.Callee)
+	}
+	for _, child := range e.Arguments {
+		children = append(children, child)
+	}
+	return children
+}
+
+func (e *Call) Line() int {
+	if e.Callee != nil {
+		if line := e.Callee.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Operator != nil {
+		return e.Operator.Line()
+	}
+	for _, child := range e.Arguments {
+		if line := child.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Call) Clone() Expr {
+	c := *e
+	c.Callee = cloneExpr(e.Callee)
+	c.Arguments = cloneExprs(e.Arguments)
+	return &c
+}
+
+func (e *Call) TransformChildren(fn func(Node) Node) {
+	if e.Callee != nil {
+		e.Callee = toExpr(fn(e.Callee))
+	}
+	e.Arguments = transformExprs(e.Arguments, fn)
+}
+
+func (e *Get) Children() []Node {
+	var children []Node
+	if e.Object != nil {
+		children = append(children, e.Object)
+	}
+	return children
+}
+
+func (e *Get) Line() int {
+	if e.Object != nil {
+		if line := e.Object.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	return 0
+}
+
+func (e *Get) Clone() Expr {
+	c := *e
+	c.Object = cloneExpr(e.Object)
+	return &c
+}
+
+func (e *Get) TransformChildren(fn func(Node) Node) {
+	if e.Object != nil {
+		e.Object = toExpr(fn(e.Object))
+	}
+}
+
+func (e *Grouping) Children() []Node {
+	var children []Node
+	if e.Expression != nil {
+		children = append(children, e.Expression)
+	}
+	return children
+}
+
+func (e *Grouping) Line() int {
+	if e.Expression != nil {
+		if line := e.Expression.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Grouping) Clone() Expr {
+	c := *e
+	c.Expression = cloneExpr(e.Expression)
+	return &c
+}
+
+func (e *Grouping) TransformChildren(fn func(Node) Node) {
+	if e.Expression != nil {
+		e.Expression = toExpr(fn(e.Expression))
+	}
+}
+
+func (e *Literal) Children() []Node {
+	return nil
+}
+
+func (e *Literal) Line() int {
+	if e.Token != nil {
+		return e.Token.Line()
+	}
+	return 0
+}
+
+func (e *Literal) Clone() Expr {
+	c := *e
+	return &c
+}
+
+func (e *Literal) TransformChildren(fn func(Node) Node) {
+}
+
+func (e *Logical) Children() []Node {
+	var children []Node
+	if e.Left != nil {
+		children = append(children, e.Left)
+	}
+	if e.Right != nil {
+		children = append(children, e.Right)
+	}
+	return children
+}
+
+func (e *Logical) Line() int {
+	if e.Left != nil {
+		if line := e.Left.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Operator != nil {
+		return e.Operator.Line()
+	}
+	if e.Right != nil {
+		if line := e.Right.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Logical) Clone() Expr {
+	c := *e
+	c.Left = cloneExpr(e.Left)
+	c.Right = cloneExpr(e.Right)
+	return &c
+}
+
+func (e *Logical) TransformChildren(fn func(Node) Node) {
+	if e.Left != nil {
+		e.Left = toExpr(fn(e.Left))
+	}
+	if e.Right != nil {
+		e.Right = toExpr(fn(e.Right))
+	}
+}
+
+func (e *Unary) Children() []Node {
+	var children []Node
+	if e.Right != nil {
+		children = append(children, e.Right)
+	}
+	return children
+}
+
+func (e *Unary) Line() int {
+	if e.Operator != nil {
+		return e.Operator.Line()
+	}
+	if e.Right != nil {
+		if line := e.Right.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *Unary) Clone() Expr {
+	c := *e
+	c.Right = cloneExpr(e.Right)
+	return &c
+}
+
+func (e *Unary) TransformChildren(fn func(Node) Node) {
+	if e.Right != nil {
+		e.Right = toExpr(fn(e.Right))
+	}
+}
+
+func (e *Variable) Children() []Node {
+	return nil
+}
+
+func (e *Variable) Line() int {
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	return 0
+}
+
+func (e *Variable) Clone() Expr {
+	c := *e
+	return &c
+}
+
+func (e *Variable) TransformChildren(fn func(Node) Node) {
+}
+
+func cloneStmt(stmt Stmt) Stmt {
+	if stmt == nil {
+		return nil
+	}
+	return stmt.Clone()
+}
+
+func cloneStmts(list []Stmt) []Stmt {
+	if list == nil {
+		return nil
+	}
+	cloned := make([]Stmt, len(list))
+	for k, item := range list {
+		cloned[k] = cloneStmt(item)
+	}
+	return cloned
+}
+
+func toStmt(node Node) Stmt {
+	if node == nil {
+		return nil
+	}
+	stmt, ok := node.(Stmt)
+	if !ok {
+		panic(transformMismatch(node, "Stmt"))
+	}
+	return stmt
+}
+
+func transformStmts(list []Stmt, fn func(Node) Node) []Stmt {
+	kept := list[:0]
+	for _, item := range list {
+		if item = toStmt(fn(item)); item != nil {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func (e *ExprStmt) Children() []Node {
+	var children []Node
+	if e.Expression != nil {
+		children = append(children, e.Expression)
+	}
+	return children
+}
+
+func (e *ExprStmt) Line() int {
+	if e.Expression != nil {
+		if line := e.Expression.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *ExprStmt) Clone() Stmt {
+	c := *e
+	c.Expression = cloneExpr(e.Expression)
+	return &c
+}
+
+func (e *ExprStmt) TransformChildren(fn func(Node) Node) {
+	if e.Expression != nil {
+		e.Expression = toExpr(fn(e.Expression))
+	}
+}
+
+func (e *FunctionStmt) Children() []Node {
+	var children []Node
+	for _, child := range e.Body {
+		children = append(children, child)
+	}
+	return children
+}
+
+func (e *FunctionStmt) Line() int {
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	if len(e.Params) > 0 {
+		return e.Params[0].Line()
+	}
+	for _, child := range e.Body {
+		if line := child.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *FunctionStmt) Clone() Stmt {
+	c := *e
+	c.Params = append([]Token(nil), e.Params...)
+	c.Body = cloneStmts(e.Body)
+	return &c
+}
+
+func (e *FunctionStmt) TransformChildren(fn func(Node) Node) {
+	e.Body = transformStmts(e.Body, fn)
+}
+
+func (e *IfStmt) Children() []Node {
+	var children []Node
+	if e.Condition != nil {
+		children = append(children, e.Condition)
+	}
+	if e.Then != nil {
+		children = append(children, e.Then)
+	}
+	if e.Else != nil {
+		children = append(children, e.Else)
+	}
+	return children
+}
+
+func (e *IfStmt) Line() int {
+	if e.Condition != nil {
+		if line := e.Condition.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Then != nil {
+		if line := e.Then.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Else != nil {
+		if line := e.Else.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *IfStmt) Clone() Stmt {
+	c := *e
+	c.Condition = cloneExpr(e.Condition)
+	c.Then = cloneStmt(e.Then)
+	c.Else = cloneStmt(e.Else)
+	return &c
+}
+
+func (e *IfStmt) TransformChildren(fn func(Node) Node) {
+	if e.Condition != nil {
+		e.Condition = toExpr(fn(e.Condition))
+	}
+	if e.Then != nil {
+		e.Then = toStmt(fn(e.Then))
+	}
+	if e.Else != nil {
+		e.Else = toStmt(fn(e.Else))
+	}
+}
+
+func (e *WhileStmt) Children() []Node {
+	var children []Node
+	if e.Condition != nil {
+		children = append(children, e.Condition)
+	}
+	if e.Statement != nil {
+		children = append(children, e.Statement)
+	}
+	return children
+}
+
+func (e *WhileStmt) Line() int {
+	if e.Condition != nil {
+		if line := e.Condition.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Statement != nil {
+		if line := e.Statement.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *WhileStmt) Clone() Stmt {
+	c := *e
+	c.Condition = cloneExpr(e.Condition)
+	c.Statement = cloneStmt(e.Statement)
+	return &c
+}
+
+func (e *WhileStmt) TransformChildren(fn func(Node) Node) {
+	if e.Condition != nil {
+		e.Condition = toExpr(fn(e.Condition))
+	}
+	if e.Statement != nil {
+		e.Statement = toStmt(fn(e.Statement))
+	}
+}
+
+func (e *VarDeclStmt) Children() []Node {
+	var children []Node
+	if e.Initializer != nil {
+		children = append(children, e.Initializer)
+	}
+	return children
+}
+
+func (e *VarDeclStmt) Line() int {
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	if e.Initializer != nil {
+		if line := e.Initializer.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *VarDeclStmt) Clone() Stmt {
+	c := *e
+	c.Initializer = cloneExpr(e.Initializer)
+	return &c
+}
+
+func (e *VarDeclStmt) TransformChildren(fn func(Node) Node) {
+	if e.Initializer != nil {
+		e.Initializer = toExpr(fn(e.Initializer))
+	}
+}
+
+func (e *BlockStmt) Children() []Node {
+	var children []Node
+	for _, child := range e.Statements {
+		children = append(children, child)
+	}
+	return children
+}
+
+func (e *BlockStmt) Line() int {
+	for _, child := range e.Statements {
+		if line := child.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Brace != nil {
+		return e.Brace.Line()
+	}
+	return 0
+}
+
+func (e *BlockStmt) Clone() Stmt {
+	c := *e
+	c.Statements = cloneStmts(e.Statements)
+	return &c
+}
+
+func (e *BlockStmt) TransformChildren(fn func(Node) Node) {
+	e.Statements = transformStmts(e.Statements, fn)
+}
+
+func (e *ReturnStmt) Children() []Node {
+	var children []Node
+	if e.Value != nil {
+		children = append(children, e.Value)
+	}
+	return children
+}
+
+func (e *ReturnStmt) Line() int {
+	if e.Keyword != nil {
+		return e.Keyword.Line()
+	}
+	if e.Value != nil {
+		if line := e.Value.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *ReturnStmt) Clone() Stmt {
+	c := *e
+	c.Value = cloneExpr(e.Value)
+	return &c
+}
+
+func (e *ReturnStmt) TransformChildren(fn func(Node) Node) {
+	if e.Value != nil {
+		e.Value = toExpr(fn(e.Value))
+	}
+}
+
+func (e *PrintStmt) Children() []Node {
+	var children []Node
+	if e.Expression != nil {
+		children = append(children, e.Expression)
+	}
+	return children
+}
+
+func (e *PrintStmt) Line() int {
+	if e.Expression != nil {
+		if line := e.Expression.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *PrintStmt) Clone() Stmt {
+	c := *e
+	c.Expression = cloneExpr(e.Expression)
+	return &c
+}
+
+func (e *PrintStmt) TransformChildren(fn func(Node) Node) {
+	if e.Expression != nil {
+		e.Expression = toExpr(fn(e.Expression))
+	}
+}
+
+func (e *SpawnStmt) Children() []Node {
+	var children []Node
+	if e.Call != nil {
+		children = append(children, e.Call)
+	}
+	return children
+}
+
+func (e *SpawnStmt) Line() int {
+	if e.Keyword != nil {
+		return e.Keyword.Line()
+	}
+	if e.Call != nil {
+		if line := e.Call.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *SpawnStmt) Clone() Stmt {
+	c := *e
+	if e.Call != nil {
+		c.Call = e.Call.Clone().(*Call)
+	}
+	return &c
+}
+
+func (e *SpawnStmt) TransformChildren(fn func(Node) Node) {
+	if e.Call != nil {
+		result := fn(e.Call)
+		node, ok := result.(*Call)
+		if !ok && result != nil {
+			panic(transformMismatch(result, "*Call"))
+		}
+		e.Call = node
+	}
+}
+
+func (e *SelectStmt) Children() []Node {
+	var children []Node
+	for _, part := range e.Cases {
+		children = append(children, part.Children()...)
+	}
+	if e.Default != nil {
+		children = append(children, e.Default)
+	}
+	return children
+}
+
+func (e *SelectStmt) Line() int {
+	if e.Keyword != nil {
+		return e.Keyword.Line()
+	}
+	for _, child := range e.Cases {
+		if line := child.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Default != nil {
+		if line := e.Default.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *SelectStmt) Clone() Stmt {
+	c := *e
+	if e.Cases != nil {
+		c.Cases = make([]*SelectCase, len(e.Cases))
+		for k, part := range e.Cases {
+			c.Cases[k] = part.Clone()
+		}
+	}
+	if e.Default != nil {
+		c.Default = e.Default.Clone().(*BlockStmt)
+	}
+	return &c
+}
+
+func (e *SelectStmt) TransformChildren(fn func(Node) Node) {
+	for _, part := range e.Cases {
+		part.TransformChildren(fn)
+	}
+	if e.Default != nil {
+		result := fn(e.Default)
+		node, ok := result.(*BlockStmt)
+		if !ok && result != nil {
+			panic(transformMismatch(result, "*BlockStmt"))
+		}
+		e.Default = node
+	}
+}
+
+func (e *YieldStmt) Children() []Node {
+	var children []Node
+	if e.Value != nil {
+		children = append(children, e.Value)
+	}
+	return children
+}
+
+func (e *YieldStmt) Line() int {
+	if e.Keyword != nil {
+		return e.Keyword.Line()
+	}
+	if e.Value != nil {
+		if line := e.Value.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *YieldStmt) Clone() Stmt {
+	c := *e
+	c.Value = cloneExpr(e.Value)
+	return &c
+}
+
+func (e *YieldStmt) TransformChildren(fn func(Node) Node) {
+	if e.Value != nil {
+		e.Value = toExpr(fn(e.Value))
+	}
+}
+
+func (e *ForInStmt) Children() []Node {
+	var children []Node
+	if e.Iterable != nil {
+		children = append(children, e.Iterable)
+	}
+	if e.Body != nil {
+		children = append(children, e.Body)
+	}
+	return children
+}
+
+func (e *ForInStmt) Line() int {
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	if e.Iterable != nil {
+		if line := e.Iterable.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Body != nil {
+		if line := e.Body.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *ForInStmt) Clone() Stmt {
+	c := *e
+	c.Iterable = cloneExpr(e.Iterable)
+	c.Body = cloneStmt(e.Body)
+	return &c
+}
+
+func (e *ForInStmt) TransformChildren(fn func(Node) Node) {
+	if e.Iterable != nil {
+		e.Iterable = toExpr(fn(e.Iterable))
+	}
+	if e.Body != nil {
+		e.Body = toStmt(fn(e.Body))
+	}
+}
+
+func (e *SelectCase) Children() []Node {
+	var children []Node
+	if e.Operation != nil {
+		children = append(children, e.Operation)
+	}
+	if e.Body != nil {
+		children = append(children, e.Body)
+	}
+	return children
+}
+
+func (e *SelectCase) Line() int {
+	if e.Name != nil {
+		return e.Name.Line()
+	}
+	if e.Operation != nil {
+		if line := e.Operation.Line(); line != 0 {
+			return line
+		}
+	}
+	if e.Body != nil {
+		if line := e.Body.Line(); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+func (e *SelectCase) Clone() *SelectCase {
+	c := *e
+	if e.Operation != nil {
+		c.Operation = e.Operation.Clone().(*Call)
+	}
+	if e.Body != nil {
+		c.Body = e.Body.Clone().(*BlockStmt)
+	}
+	return &c
+}
+
+func (e *SelectCase) TransformChildren(fn func(Node) Node) {
+	if e.Operation != nil {
+		result := fn(e.Operation)
+		node, ok := result.(*Call)
+		if !ok && result != nil {
+			panic(transformMismatch(result, "*Call"))
+		}
+		e.Operation = node
+	}
+	if e.Body != nil {
+		result := fn(e.Body)
+		node, ok := result.(*BlockStmt)
+		if !ok && result != nil {
+			panic(transformMismatch(result, "*BlockStmt"))
+		}
+		e.Body = node
+	}
+}
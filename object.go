@@ -0,0 +1,96 @@
+package main
+
+import "fmt"
+
+type Getter interface {
+	Get(i *Interpreter, name Token) Value
+}
+
+type Iterator interface {
+	Next(i *Interpreter) (Value, bool)
+}
+
+type Iterable interface {
+	Iterator(i *Interpreter) Iterator
+}
+
+func (i *Interpreter) VisitGet(expr *Get) Value {
+	object := i.evaluate(expr.Object)
+	if str, ok := object.AsString(); ok {
+		return i.stringMethod(str, expr.Name)
+	}
+
+	getter, ok := object.AsObject().(Getter)
+	if !ok {
+		panic(i.error(expr.Name, "Only objects have properties."))
+	}
+
+	return getter.Get(i, expr.Name)
+}
+
+func (i *Interpreter) VisitForInStmt(stmt *ForInStmt) {
+	var iterator Iterator
+	value := i.evaluate(stmt.Iterable)
+	if str, ok := value.AsString(); ok {
+		iterator = &stringIterator{runes: []rune(str)}
+	} else if iterable, ok := value.AsObject().(Iterable); ok {
+		iterator = iterable.Iterator(i)
+	} else {
+		panic(i.error(stmt.Name, "Can only iterate over iterable values."))
+	}
+
+	for {
+		val, ok := i.nextItem(iterator, stmt.Name)
+		if !ok {
+			return
+		}
+
+		i.allocateEnvironment(stmt.Name, 1)
+		env := NewEnvironment(i.Env)
+		env.Define(string(stmt.Name.Lexeme()), val)
+		i.executeBlock([]Stmt{stmt.Body}, env)
+	}
+}
+
+var iteratorNext = NewNativeFunction("next", 0, nil)
+
+func (i *Interpreter) nextItem(iterator Iterator, token Token) (Value, bool) {
+	if i.depth() >= i.maxCallDepth {
+		panic(i.error(token, "Stack overflow."))
+	}
+
+	i.callStack = append(i.callStack, callFrame{callee: iteratorNext, token: token})
+	val, ok := iterator.Next(i)
+	i.callStack = i.callStack[:len(i.callStack)-1]
+
+	return val, ok
+}
+
+func (i *Interpreter) undefinedProperty(name Token) *RuntimeError {
+	msg := fmt.Sprintf("Undefined property '%s'.", string(name.Lexeme()))
+	return i.error(name, msg)
+}
+
+type Module struct {
+	name    string
+	members map[string]Value
+}
+
+func NewModule(name string, members map[string]Value) *Module {
+	return &Module{
+		name:    name,
+		members: members,
+	}
+}
+
+func (m *Module) Get(i *Interpreter, name Token) Value {
+	if member, ok := m.members[string(name.Lexeme())]; ok {
+		return member
+	}
+
+	panic(i.undefinedProperty(name))
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("<module %s>", m.name)
+}
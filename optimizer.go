@@ -0,0 +1,153 @@
+package main
+
+import "github.com/roycefanproxy/yaglox/constant"
+
+// Optimize folds constant expressions and drops code that can never run. It
+// rewrites statements in place and only removes nodes that can't fail at
+// runtime, so every remaining error still points at its original line.
+func Optimize(statements []Stmt) []Stmt {
+	return pruneStmts(TransformProgram(statements, optimizeNode))
+}
+
+func optimizeNode(node Node) Node {
+	switch n := node.(type) {
+	case *Grouping:
+		if literal, ok := n.Expression.(*Literal); ok {
+			return literal
+		}
+	case *Unary:
+		return foldUnary(n)
+	case *Binary:
+		return foldBinary(n)
+	case *Logical:
+		return foldLogical(n)
+	case *ExprStmt:
+		if _, ok := n.Expression.(*Literal); ok {
+			return &BlockStmt{}
+		}
+	case *IfStmt:
+		condition, ok := n.Condition.(*Literal)
+		if !ok {
+			break
+		}
+		if isTruthy(ValueOf(condition.Value)) {
+			return n.Then
+		}
+		if n.Else != nil {
+			return n.Else
+		}
+		return &BlockStmt{}
+	case *WhileStmt:
+		if condition, ok := n.Condition.(*Literal); ok && !isTruthy(ValueOf(condition.Value)) {
+			return &BlockStmt{}
+		}
+	case *BlockStmt:
+		n.Statements = pruneStmts(n.Statements)
+	case *FunctionStmt:
+		n.Body = pruneStmts(n.Body)
+	}
+
+	return node
+}
+
+func foldUnary(expr *Unary) Expr {
+	right, ok := expr.Right.(*Literal)
+	if !ok {
+		return expr
+	}
+
+	switch expr.Operator.Type() {
+	case constant.Bang:
+		return &Literal{Value: !isTruthy(ValueOf(right.Value)), Token: expr.Operator}
+	case constant.Minus:
+		if num, ok := right.Value.(float64); ok {
+			return &Literal{Value: -num, Token: expr.Operator}
+		}
+	}
+
+	return expr
+}
+
+func foldBinary(expr *Binary) Expr {
+	left, isLeftLiteral := expr.Left.(*Literal)
+	right, isRightLiteral := expr.Right.(*Literal)
+	if !isLeftLiteral || !isRightLiteral {
+		return expr
+	}
+
+	switch expr.Operator.Type() {
+	case constant.EqualEqual:
+		return &Literal{Value: isEqual(ValueOf(left.Value), ValueOf(right.Value)), Token: expr.Operator}
+	case constant.BangEqual:
+		return &Literal{Value: !isEqual(ValueOf(left.Value), ValueOf(right.Value)), Token: expr.Operator}
+	}
+
+	if lStr, ok := left.Value.(string); ok && expr.Operator.Type() == constant.Plus {
+		if rStr, ok := right.Value.(string); ok {
+			return &Literal{Value: lStr + rStr, Token: expr.Operator}
+		}
+		return expr
+	}
+
+	lNum, isLeftNum := left.Value.(float64)
+	rNum, isRightNum := right.Value.(float64)
+	if !isLeftNum || !isRightNum {
+		return expr
+	}
+
+	switch expr.Operator.Type() {
+	case constant.Plus:
+		return &Literal{Value: lNum + rNum, Token: expr.Operator}
+	case constant.Minus:
+		return &Literal{Value: lNum - rNum, Token: expr.Operator}
+	case constant.Star:
+		return &Literal{Value: lNum * rNum, Token: expr.Operator}
+	case constant.Slash:
+		return &Literal{Value: lNum / rNum, Token: expr.Operator}
+	case constant.Greater:
+		return &Literal{Value: lNum > rNum, Token: expr.Operator}
+	case constant.GreaterEqual:
+		return &Literal{Value: lNum >= rNum, Token: expr.Operator}
+	case constant.Less:
+		return &Literal{Value: lNum < rNum, Token: expr.Operator}
+	case constant.LessEqual:
+		return &Literal{Value: lNum <= rNum, Token: expr.Operator}
+	}
+
+	return expr
+}
+
+func foldLogical(expr *Logical) Expr {
+	left, ok := expr.Left.(*Literal)
+	if !ok {
+		return expr
+	}
+
+	if isLeftTruthy := isTruthy(ValueOf(left.Value)); expr.Operator.Type() == constant.Or {
+		if isLeftTruthy {
+			return left
+		}
+	} else {
+		if !isLeftTruthy {
+			return left
+		}
+	}
+
+	return expr.Right
+}
+
+func pruneStmts(statements []Stmt) []Stmt {
+	kept := statements[:0]
+	for _, stmt := range statements {
+		if block, ok := stmt.(*BlockStmt); ok && len(block.Statements) == 0 {
+			continue
+		}
+
+		kept = append(kept, stmt)
+		if _, ok := stmt.(*ReturnStmt); ok {
+			break
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestOptimizerKeepsBehavior(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		stdout string
+		stderr string
+	}{
+		{"arithmetic", "print 1 + 2 * 3 - 4 / 2;", "5\n", ""},
+		{"comparison", "print 1 < 2 == true;", "true\n", ""},
+		{"strings", `print "a" + "b" + "c";`, "abc\n", ""},
+		{"unary", "print -(2 + 3); print !nil;", "-5\ntrue\n", ""},
+		{"logical", `print false or "x"; print nil and 1; print 1 or f();`, "x\nnil\n1\n", ""},
+		{"dead if", "if (false) print 1; else print 2; if (1 > 2) print 3;", "2\n", ""},
+		{"live if", `if ("yes") print 1; else print 2;`, "1\n", ""},
+		{"dead while", "while (false) print 1; print 2;", "2\n", ""},
+		{"after return", "func f() { print 1; return 2; print 3; } print f();", "1\n2\n", ""},
+		{"unused expressions", `1 + 2; "a"; print 3;`, "3\n", ""},
+		{"variables stay", "var a = 1; print a + 2 * 3;", "7\n", ""},
+		{"folded error line", "print 1;\nprint -\"a\";\n", "1\n", "Operand must be a number.\n[line 2]\n"},
+		{"folded operands error line", "print 1;\n\nprint (1 + 2) - \"a\";\n", "1\n", "Operands must be numbers.\n[line 3]\n"},
+		{"error after folded code", "var x = 2 * 3;\nprint x;\nprint x + nil;\n", "6\n", "Operands must be two numbers or two strings.\n[line 3]\n"},
+	}
+
+	for _, test := range tests {
+		for _, optimize := range []bool{false, true} {
+			var stdout, stderr bytes.Buffer
+			lox := NewLox(Config{Stdout: &stdout, Stderr: &stderr, Optimize: optimize})
+			lox.Run(test.source)
+			lox.Close()
+
+			if stdout.String() != test.stdout || stderr.String() != test.stderr {
+				t.Errorf("%s (optimize=%v): got %q and error %q, want %q and error %q",
+					test.name, optimize, stdout.String(), stderr.String(), test.stdout, test.stderr)
+			}
+		}
+	}
+}
+
+func TestOptimizerFolds(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"print 1 + 2 * 3;", "(print 7)\n"},
+		{`print "a" + "b";`, "(print \"ab\")\n"},
+		{"print !true == false;", "(print true)\n"},
+		{"if (false) print 1; else print 2;", "(print 2)\n"},
+		{"if (false) print 1; print 2;", "(print 2)\n"},
+		{"while (false) print 1; print 2;", "(print 2)\n"},
+		{"1 + 2; print 3;", "(print 3)\n"},
+		{"print 1 + nil;", "(print (+ 1 nil))\n"},
+	}
+
+	for _, test := range tests {
+		statements := NewLox(Config{Stderr: io.Discard}).Parse(test.source)
+		if got := (ASTPrinter{}).PrintProgram(Optimize(statements)); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.source, got, test.want)
+		}
+	}
+}
@@ -7,13 +7,19 @@ import (
 )
 
 type Parser struct {
-	tokens  []Token
-	current int
+	tokens   []Token
+	current  int
+	reporter *ErrorReporter
+	// functions is how many function bodies enclose the current token;
+	// yields records whether each of those bodies yields.
+	functions int
+	yields    []bool
 }
 
-func NewParser(tokens []Token) *Parser {
+func NewParser(tokens []Token, reporter *ErrorReporter) *Parser {
 	return &Parser{
-		tokens: tokens,
+		tokens:   tokens,
+		reporter: reporter,
 	}
 }
 
@@ -44,6 +50,16 @@ func (p *Parser) declaration() Stmt {
 	if p.match(constant.Func) {
 		return p.functionStatement("function")
 	}
+	if p.match(constant.Async) {
+		keyword := p.previous()
+		p.consume(constant.Func, "Expect 'func' after 'async'.")
+		function := p.functionStatement("function").(*FunctionStmt)
+		if function.Generator {
+			p.error(keyword, "Async functions can't yield.")
+		}
+		function.Async = true
+		return function
+	}
 
 	return p.statement()
 }
@@ -83,6 +99,15 @@ func (p *Parser) statement() Stmt {
 	if p.match(constant.Print) {
 		return p.printStatement()
 	}
+	if p.match(constant.Spawn) {
+		return p.spawnStatement()
+	}
+	if p.match(constant.Select) {
+		return p.selectStatement()
+	}
+	if p.match(constant.Yield) {
+		return p.yieldStatement()
+	}
 
 	return p.expressionStatement()
 }
@@ -119,7 +144,12 @@ func (p *Parser) whileStatement() Stmt {
 }
 
 func (p *Parser) forStatement() Stmt {
+	keyword := p.previous()
 	p.consume(constant.LeftParen, "Expect '(' after 'while'.")
+	if p.check(constant.Var) && p.checkAt(1, constant.Identifier) && p.checkAt(2, constant.In) {
+		return p.forInStatement()
+	}
+
 	var initializer Stmt
 	if p.match(constant.Semicolon) {
 	} else if p.match(constant.Var) {
@@ -148,11 +178,12 @@ func (p *Parser) forStatement() Stmt {
 				statement,
 				&ExprStmt{Expression: tailExpression},
 			},
+			Brace: keyword,
 		}
 	}
 
 	if condition == nil {
-		condition = &Literal{Value: true}
+		condition = &Literal{Value: true, Token: keyword}
 	}
 
 	statement = &WhileStmt{
@@ -166,20 +197,41 @@ func (p *Parser) forStatement() Stmt {
 				initializer,
 				statement,
 			},
+			Brace: keyword,
 		}
 	}
 
 	return statement
 }
 
+func (p *Parser) forInStatement() Stmt {
+	p.consume(constant.Var, "Expect 'var' in for-in loop.")
+	name := p.consume(constant.Identifier, "Expect variable name.")
+	p.consume(constant.In, "Expect 'in' after loop variable.")
+	iterable := p.expression()
+	p.consume(constant.RightParen, "Expect ')' after for-in clause.")
+
+	return &ForInStmt{
+		Name:     name,
+		Iterable: iterable,
+		Body:     p.statement(),
+	}
+}
+
 func (p *Parser) blockStatement() Stmt {
+	brace := p.previous()
+
 	return &BlockStmt{
 		Statements: p.statementsInBlock(),
+		Brace:      brace,
 	}
 }
 
 func (p *Parser) returnStatement() Stmt {
 	keyword := p.previous()
+	if p.functions == 0 {
+		p.error(keyword, "Can't return from top-level code.")
+	}
 	var val Expr
 
 	if !p.check(constant.Semicolon) {
@@ -200,6 +252,88 @@ func (p *Parser) printStatement() Stmt {
 		Expression: expr,
 	}
 }
+
+func (p *Parser) spawnStatement() Stmt {
+	keyword := p.previous()
+	expr := p.call()
+	call, ok := expr.(*Call)
+	if !ok {
+		panic(p.error(keyword, "Expect function call after 'spawn'."))
+	}
+	p.consume(constant.Semicolon, "Expect ';' after spawn statement.")
+
+	return &SpawnStmt{
+		Keyword: keyword,
+		Call:    call,
+	}
+}
+
+func (p *Parser) selectStatement() Stmt {
+	keyword := p.previous()
+	p.consume(constant.LeftBrace, "Expect '{' after 'select'.")
+
+	cases := []*SelectCase{}
+	var defaultBody *BlockStmt
+	for !p.check(constant.RightBrace) && !p.isAtEnd() {
+		if p.match(constant.Default) {
+			if defaultBody != nil {
+				p.error(p.previous(), "Select can't have more than one default case.")
+			}
+			p.consume(constant.LeftBrace, "Expect '{' after 'default'.")
+			defaultBody = p.blockStatement().(*BlockStmt)
+			continue
+		}
+
+		p.consume(constant.Case, "Expect 'case' or 'default' in select.")
+		var name Token
+		if p.match(constant.Var) {
+			name = p.consume(constant.Identifier, "Expect variable name.")
+			p.consume(constant.Equal, "Expect '=' after variable name.")
+		}
+
+		expr := p.call()
+		operation, ok := expr.(*Call)
+		if !ok {
+			panic(p.error(p.previous(), "Expect send or receive call in select case."))
+		}
+
+		p.consume(constant.LeftBrace, "Expect '{' before case body.")
+		cases = append(cases, &SelectCase{
+			Name:      name,
+			Operation: operation,
+			Body:      p.blockStatement().(*BlockStmt),
+		})
+	}
+
+	p.consume(constant.RightBrace, "Expect '}' at the end of select.")
+
+	return &SelectStmt{
+		Keyword: keyword,
+		Cases:   cases,
+		Default: defaultBody,
+	}
+}
+
+func (p *Parser) yieldStatement() Stmt {
+	keyword := p.previous()
+	if p.functions == 0 {
+		p.error(keyword, "Can't yield outside of a function.")
+	} else {
+		p.yields[len(p.yields)-1] = true
+	}
+
+	var val Expr
+	if !p.check(constant.Semicolon) {
+		val = p.expression()
+	}
+	p.consume(constant.Semicolon, "Expect ';' after yield value.")
+
+	return &YieldStmt{
+		Keyword: keyword,
+		Value:   val,
+	}
+}
+
 func (p *Parser) expressionStatement() Stmt {
 	expr := p.expression()
 	p.consume(constant.Semicolon, "Expect ';' after value.")
@@ -233,11 +367,20 @@ func (p *Parser) functionStatement(kind string) Stmt {
 
 	msg = fmt.Sprintf("Expect '{' before %s body.", kind)
 	p.consume(constant.LeftBrace, msg)
+
+	p.functions++
+	p.yields = append(p.yields, false)
+	defer func() {
+		p.functions--
+		p.yields = p.yields[:len(p.yields)-1]
+	}()
 	body := p.statementsInBlock()
+
 	return &FunctionStmt{
-		Name:   name,
-		Params: params,
-		Body:   body,
+		Name:      name,
+		Params:    params,
+		Body:      body,
+		Generator: p.yields[len(p.yields)-1],
 	}
 }
 
@@ -304,6 +447,14 @@ func (p *Parser) factor() Expr {
 }
 
 func (p *Parser) unary() Expr {
+	if p.match(constant.Await) {
+		keyword := p.previous()
+		value := p.unary()
+		return &Await{
+			Keyword: keyword,
+			Value:   value,
+		}
+	}
 	if p.match(constant.Bang, constant.Minus) {
 		operator := p.previous()
 		right := p.unary()
@@ -322,6 +473,12 @@ func (p *Parser) call() Expr {
 	for {
 		if p.match(constant.LeftParen) {
 			expr = p.finishCall(expr)
+		} else if p.match(constant.Dot) {
+			name := p.consume(constant.Identifier, "Expect property name after '.'.")
+			expr = &Get{
+				Object: expr,
+				Name:   name,
+			}
 		} else {
 			break
 		}
@@ -360,13 +517,13 @@ func (p *Parser) primary() (expr Expr) {
 
 	switch p.peek().Type() {
 	case constant.False:
-		expr = &Literal{Value: false}
+		expr = &Literal{Value: false, Token: p.peek()}
 	case constant.True:
-		expr = &Literal{Value: true}
+		expr = &Literal{Value: true, Token: p.peek()}
 	case constant.Nil:
-		expr = &Literal{Value: nil}
+		expr = &Literal{Value: nil, Token: p.peek()}
 	case constant.Number, constant.String:
-		expr = &Literal{Value: p.peek().Literal()}
+		expr = &Literal{Value: p.peek().Literal(), Token: p.peek()}
 	case constant.Identifier:
 		expr = &Variable{Name: p.peek()}
 	case constant.LeftParen:
@@ -413,6 +570,14 @@ func (p *Parser) check(tokenType constant.TokenType) bool {
 	return p.peek().Type() == tokenType
 }
 
+func (p *Parser) checkAt(offset int, tokenType constant.TokenType) bool {
+	if p.current+offset >= len(p.tokens) {
+		return false
+	}
+
+	return p.tokens[p.current+offset].Type() == tokenType
+}
+
 func (p *Parser) advance() Token {
 	if !p.isAtEnd() {
 		p.current++
@@ -433,8 +598,8 @@ func (p *Parser) previous() Token {
 	return p.tokens[p.current-1]
 }
 
-func (*Parser) error(token Token, msg string) string {
-	return EmitParseError(token, msg)
+func (p *Parser) error(token Token, msg string) string {
+	return p.reporter.EmitParseError(token, msg)
 }
 
 func (p *Parser) Synchronize() {
@@ -446,8 +611,9 @@ func (p *Parser) Synchronize() {
 		}
 
 		switch p.peek().Type() {
-		case constant.Class, constant.Func, constant.Var, constant.For,
-			constant.If, constant.While, constant.Print, constant.Return:
+		case constant.Class, constant.Func, constant.Async, constant.Var, constant.For,
+			constant.If, constant.While, constant.Print, constant.Return,
+			constant.Spawn, constant.Select, constant.Yield:
 			return
 		}
 
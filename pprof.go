@@ -0,0 +1,210 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+)
+
+// Field numbers from github.com/google/pprof/proto/profile.proto.
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+// protoBuffer encodes the handful of protobuf wire types a profile needs.
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *protoBuffer) key(field, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protoBuffer) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(x)
+}
+
+func (b *protoBuffer) int64(field int, x int64) {
+	b.uint64(field, uint64(x))
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protoBuffer) packed(field int, xs []uint64) {
+	var inner protoBuffer
+	for _, x := range xs {
+		inner.varint(x)
+	}
+	b.bytes(field, inner.data)
+}
+
+func (b *protoBuffer) message(field int, encode func(*protoBuffer)) {
+	var inner protoBuffer
+	encode(&inner)
+	b.bytes(field, inner.data)
+}
+
+type stringTable struct {
+	strings []string
+	index   map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{
+		strings: []string{""},
+		index:   map[string]int64{"": 0},
+	}
+}
+
+func (t *stringTable) add(str string) int64 {
+	if index, ok := t.index[str]; ok {
+		return index
+	}
+
+	index := int64(len(t.strings))
+	t.strings = append(t.strings, str)
+	t.index[str] = index
+	return index
+}
+
+// WritePprof writes the call tree as a gzipped pprof profile with a call
+// count and an exclusive time for every distinct Lox call stack, so that
+// "go tool pprof" can show Lox functions in its usual views.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	p.mu.Lock()
+	data := p.encodePprof()
+	p.mu.Unlock()
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (p *Profiler) encodePprof() []byte {
+	var b protoBuffer
+	strs := newStringTable()
+
+	valueType := func(field int, typ, unit string) {
+		b.message(field, func(m *protoBuffer) {
+			m.int64(valueTypeType, strs.add(typ))
+			m.int64(valueTypeUnit, strs.add(unit))
+		})
+	}
+	valueType(profileSampleType, "calls", "count")
+	valueType(profileSampleType, "time", "nanoseconds")
+
+	functions := []*FunctionProfile{p.script}
+	for _, fn := range p.functions {
+		functions = append(functions, fn)
+	}
+	sort.Slice(functions[1:], func(a, b int) bool {
+		return functions[a+1].Line < functions[b+1].Line
+	})
+	ids := map[*FunctionProfile]uint64{}
+	for k, fn := range functions {
+		ids[fn] = uint64(k + 1)
+	}
+
+	var encodeNode func(node *profileNode, stack []uint64)
+	encodeNode = func(node *profileNode, stack []uint64) {
+		stack = append([]uint64{ids[node.function]}, stack...)
+		if node.calls > 0 || node.exclusive > 0 {
+			b.message(profileSample, func(m *protoBuffer) {
+				m.packed(sampleLocationID, stack)
+				m.packed(sampleValue, []uint64{uint64(node.calls), uint64(node.exclusive)})
+			})
+		}
+
+		children := make([]*profileNode, 0, len(node.children))
+		for _, child := range node.children {
+			children = append(children, child)
+		}
+		sort.Slice(children, func(a, b int) bool {
+			return ids[children[a].function] < ids[children[b].function]
+		})
+		for _, child := range children {
+			encodeNode(child, stack)
+		}
+	}
+	encodeNode(p.root, nil)
+
+	// Every function gets one location, at the line it is declared on.
+	for _, fn := range functions {
+		id := ids[fn]
+		b.message(profileLocation, func(m *protoBuffer) {
+			m.uint64(locationID, id)
+			m.message(locationLine, func(l *protoBuffer) {
+				l.uint64(lineFunctionID, id)
+				l.int64(lineLine, int64(fn.Line))
+			})
+		})
+	}
+	for _, fn := range functions {
+		id, name := ids[fn], strs.add(fn.Name)
+		b.message(profileFunction, func(m *protoBuffer) {
+			m.uint64(functionID, id)
+			m.int64(functionName, name)
+			m.int64(functionSystemName, name)
+			m.int64(functionFilename, strs.add(p.filename))
+			m.int64(functionStartLine, int64(fn.Line))
+		})
+	}
+
+	if !p.start.IsZero() {
+		b.int64(profileTimeNanos, p.start.UnixNano())
+	}
+	b.int64(profileDurationNanos, int64(p.script.Inclusive))
+	valueType(profilePeriodType, "time", "nanoseconds")
+	b.int64(profilePeriod, 1)
+
+	for _, str := range strs.strings {
+		b.bytes(profileStringTable, []byte(str))
+	}
+
+	return b.data
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profiler records how often each Lox function is called, how long it runs
+// and how many statements run on every line. Times are wall-clock times
+// measured around each call, so a generator or async function also counts
+// the time it spends suspended. One Profiler is shared by an interpreter and
+// every goroutine, async task and generator it starts.
+type Profiler struct {
+	mu        sync.Mutex
+	filename  string
+	start     time.Time
+	script    *FunctionProfile
+	root      *profileNode
+	functions map[*FunctionStmt]*FunctionProfile
+	lines     map[int]int64
+}
+
+type FunctionProfile struct {
+	Name      string
+	Line      int
+	Calls     int64
+	Inclusive time.Duration
+	Exclusive time.Duration
+}
+
+// profileNode is a node of the call tree: one per distinct stack of
+// functions, which is what the pprof output is built from.
+type profileNode struct {
+	function  *FunctionProfile
+	parent    *profileNode
+	children  map[*FunctionProfile]*profileNode
+	calls     int64
+	exclusive time.Duration
+}
+
+type profileFrame struct {
+	node      *profileNode
+	start     time.Time
+	callees   time.Duration
+	recursive bool
+}
+
+func NewProfiler(filename string) *Profiler {
+	script := &FunctionProfile{Name: "script"}
+
+	return &Profiler{
+		filename:  filename,
+		script:    script,
+		root:      &profileNode{function: script, children: map[*FunctionProfile]*profileNode{}},
+		functions: map[*FunctionStmt]*FunctionProfile{},
+		lines:     map[int]int64{},
+	}
+}
+
+func (p *Profiler) function(stmt *FunctionStmt) *FunctionProfile {
+	fn, ok := p.functions[stmt]
+	if !ok {
+		fn = &FunctionProfile{Name: string(stmt.Name.Lexeme()), Line: stmt.Line()}
+		p.functions[stmt] = fn
+	}
+
+	return fn
+}
+
+func (p *Profiler) countLine(line int) {
+	p.mu.Lock()
+	p.lines[line]++
+	p.mu.Unlock()
+}
+
+// Functions returns the profile of the script and every function that was
+// called, by descending exclusive time.
+func (p *Profiler) Functions() []FunctionProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	functions := []FunctionProfile{*p.script}
+	for _, fn := range p.functions {
+		functions = append(functions, *fn)
+	}
+	sort.SliceStable(functions, func(a, b int) bool {
+		if functions[a].Exclusive != functions[b].Exclusive {
+			return functions[a].Exclusive > functions[b].Exclusive
+		}
+		return functions[a].Line < functions[b].Line
+	})
+
+	return functions
+}
+
+// Lines returns how many statements ran on each line.
+func (p *Profiler) Lines() map[int]int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lines := make(map[int]int64, len(p.lines))
+	for line, count := range p.lines {
+		lines[line] = count
+	}
+
+	return lines
+}
+
+// WriteReport writes the function and line counts as plain text.
+func (p *Profiler) WriteReport(w io.Writer) error {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%10s  %12s  %12s  %s\n", "calls", "inclusive", "exclusive", "function")
+	for _, fn := range p.Functions() {
+		name := fn.Name
+		if fn.Line > 0 {
+			name = fmt.Sprintf("%s (%s:%d)", fn.Name, p.filename, fn.Line)
+		}
+		fmt.Fprintf(&report, "%10d  %12v  %12v  %s\n",
+			fn.Calls, roundDuration(fn.Inclusive), roundDuration(fn.Exclusive), name)
+	}
+
+	lines := p.Lines()
+	numbers := make([]int, 0, len(lines))
+	for line := range lines {
+		numbers = append(numbers, line)
+	}
+	sort.Ints(numbers)
+
+	fmt.Fprintf(&report, "\n%10s  %12s\n", "line", "statements")
+	for _, line := range numbers {
+		fmt.Fprintf(&report, "%10d  %12d\n", line, lines[line])
+	}
+
+	_, err := io.WriteString(w, report.String())
+	return err
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+// enterProfile starts timing a call to stmt, or the script itself when
+// stmt is nil.
+func (i *Interpreter) enterProfile(stmt *FunctionStmt) {
+	p := i.profiler
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn := p.script
+	if stmt != nil {
+		fn = p.function(stmt)
+	}
+
+	parent := p.root
+	if len(i.profile) > 0 {
+		parent = i.profile[len(i.profile)-1].node
+	}
+
+	node := parent
+	if fn != p.script {
+		node = parent.children[fn]
+		if node == nil {
+			node = &profileNode{function: fn, parent: parent, children: map[*FunctionProfile]*profileNode{}}
+			parent.children[fn] = node
+		}
+	} else if p.start.IsZero() {
+		p.start = now
+	}
+
+	recursive := false
+	for _, frame := range i.profile {
+		recursive = recursive || frame.node.function == fn
+	}
+
+	fn.Calls++
+	node.calls++
+	i.profile = append(i.profile, profileFrame{node: node, start: now, recursive: recursive})
+}
+
+func (i *Interpreter) exitProfile() {
+	p := i.profiler
+	frame := i.profile[len(i.profile)-1]
+	i.profile = i.profile[:len(i.profile)-1]
+	elapsed := time.Since(frame.start)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn := frame.node.function
+	fn.Exclusive += elapsed - frame.callees
+	frame.node.exclusive += elapsed - frame.callees
+	if !frame.recursive {
+		fn.Inclusive += elapsed
+	}
+	if len(i.profile) > 0 {
+		i.profile[len(i.profile)-1].callees += elapsed
+	}
+}
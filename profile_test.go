@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestProfileLines(t *testing.T) {
+	source := `print 1;
+print "a";
+{}
+for (; false;) {}
+true;
+`
+	for _, optimize := range []bool{false, true} {
+		profiler := NewProfiler("test.lox")
+		lox := NewLox(Config{Stdout: io.Discard, Profiler: profiler, Optimize: optimize})
+		lox.Run(source)
+		lox.Close()
+		if lox.HasError() || lox.HasRuntimeError() {
+			t.Fatal("the script failed")
+		}
+
+		lines := profiler.Lines()
+		if count, ok := lines[0]; ok {
+			t.Errorf("optimize=%v: %d statements counted on line 0", optimize, count)
+		}
+		want := []int{1, 2}
+		if !optimize {
+			want = append(want, 3, 4, 5)
+		}
+		for _, line := range want {
+			if lines[line] == 0 {
+				t.Errorf("optimize=%v: no statements counted on line %d", optimize, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+type scope struct {
+	slots map[string]int
+	size  int
+}
+
+// Resolver gives every variable reference the (depth, index) of its slot.
+// Each scope mirrors an Environment the interpreter creates at runtime, and
+// indexes follow the order of first declaration, which is also the order in
+// which values are appended to that Environment; a redeclaration reuses the
+// slot. A reference resolves to the innermost scope that declares the name
+// anywhere, even after the reference, so that a function can call one
+// declared after it. Names not found in any scope are globals and are
+// resolved to their slot in the global table.
+type Resolver struct {
+	globals *GlobalEnvironment
+	scopes  []*scope
+}
+
+func NewResolver(globals *GlobalEnvironment) *Resolver {
+	return &Resolver{
+		globals: globals,
+	}
+}
+
+func (r *Resolver) Resolve(statements []Stmt) {
+	for _, stmt := range statements {
+		r.resolveStmt(stmt)
+	}
+}
+
+func (r *Resolver) VisitAssign(expr *Assign) {
+	r.resolveExpr(expr.Value)
+	expr.Depth, expr.Index = r.resolveLocal(expr.Name)
+}
+
+func (r *Resolver) VisitAwait(expr *Await) {
+	r.resolveExpr(expr.Value)
+}
+
+func (r *Resolver) VisitBinary(expr *Binary) {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+}
+
+func (r *Resolver) VisitCall(expr *Call) {
+	r.resolveExpr(expr.Callee)
+	for _, arg := range expr.Arguments {
+		r.resolveExpr(arg)
+	}
+}
+
+func (r *Resolver) VisitGet(expr *Get) {
+	r.resolveExpr(expr.Object)
+}
+
+func (r *Resolver) VisitGrouping(expr *Grouping) {
+	r.resolveExpr(expr.Expression)
+}
+
+func (r *Resolver) VisitLiteral(expr *Literal) {}
+
+func (r *Resolver) VisitLogical(expr *Logical) {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+}
+
+func (r *Resolver) VisitUnary(expr *Unary) {
+	r.resolveExpr(expr.Right)
+}
+
+func (r *Resolver) VisitVariable(expr *Variable) {
+	expr.Depth, expr.Index = r.resolveLocal(expr.Name)
+}
+
+func (r *Resolver) VisitExprStmt(stmt *ExprStmt) {
+	r.resolveExpr(stmt.Expression)
+}
+
+func (r *Resolver) VisitFunctionStmt(stmt *FunctionStmt) {
+	r.declare(stmt.Name)
+
+	r.beginScope()
+	for _, param := range stmt.Params {
+		r.declare(param)
+	}
+	r.resolveScope(stmt.Body)
+	r.endScope()
+}
+
+func (r *Resolver) VisitIfStmt(stmt *IfStmt) {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.Then)
+	if stmt.Else != nil {
+		r.resolveStmt(stmt.Else)
+	}
+}
+
+func (r *Resolver) VisitWhileStmt(stmt *WhileStmt) {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.Statement)
+}
+
+func (r *Resolver) VisitVarDeclStmt(stmt *VarDeclStmt) {
+	if stmt.Initializer != nil {
+		r.resolveExpr(stmt.Initializer)
+	}
+	r.declare(stmt.Name)
+}
+
+func (r *Resolver) VisitBlockStmt(stmt *BlockStmt) {
+	r.beginScope()
+	r.resolveScope(stmt.Statements)
+	r.endScope()
+}
+
+func (r *Resolver) VisitReturnStmt(stmt *ReturnStmt) {
+	if stmt.Value != nil {
+		r.resolveExpr(stmt.Value)
+	}
+}
+
+func (r *Resolver) VisitPrintStmt(stmt *PrintStmt) {
+	r.resolveExpr(stmt.Expression)
+}
+
+func (r *Resolver) VisitSpawnStmt(stmt *SpawnStmt) {
+	r.resolveExpr(stmt.Call)
+}
+
+func (r *Resolver) VisitSelectStmt(stmt *SelectStmt) {
+	for _, c := range stmt.Cases {
+		r.resolveExpr(c.Operation)
+	}
+
+	for _, c := range stmt.Cases {
+		r.beginScope()
+		if c.Name != nil {
+			r.declare(c.Name)
+		}
+		r.resolveScope(c.Body.Statements)
+		r.endScope()
+	}
+
+	if stmt.Default != nil {
+		r.VisitBlockStmt(stmt.Default)
+	}
+}
+
+func (r *Resolver) VisitYieldStmt(stmt *YieldStmt) {
+	if stmt.Value != nil {
+		r.resolveExpr(stmt.Value)
+	}
+}
+
+func (r *Resolver) VisitForInStmt(stmt *ForInStmt) {
+	r.resolveExpr(stmt.Iterable)
+
+	r.beginScope()
+	r.declare(stmt.Name)
+	r.resolveStmt(stmt.Body)
+	r.endScope()
+}
+
+// resolveScope declares the names a scope's statements declare before
+// resolving them.
+func (r *Resolver) resolveScope(statements []Stmt) {
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *VarDeclStmt:
+			r.declare(s.Name)
+		case *FunctionStmt:
+			r.declare(s.Name)
+		}
+	}
+	r.Resolve(statements)
+}
+
+func (r *Resolver) resolveExpr(expr Expr) {
+	expr.Accept(r)
+}
+
+func (r *Resolver) resolveStmt(stmt Stmt) {
+	stmt.Accept(r)
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, &scope{slots: map[string]int{}})
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *Resolver) declare(name Token) {
+	if len(r.scopes) == 0 {
+		r.globals.Slot(string(name.Lexeme()))
+		return
+	}
+
+	current := r.scopes[len(r.scopes)-1]
+	if _, ok := current.slots[string(name.Lexeme())]; ok {
+		return
+	}
+	current.slots[string(name.Lexeme())] = current.size
+	current.size++
+}
+
+func (r *Resolver) resolveLocal(name Token) (int, int) {
+	lexeme := string(name.Lexeme())
+	for k := len(r.scopes) - 1; k >= 0; k-- {
+		if index, ok := r.scopes[k].slots[lexeme]; ok {
+			return len(r.scopes) - 1 - k, index
+		}
+	}
+
+	return globalDepth, r.globals.Slot(lexeme)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestResolverKeepsNameLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "call a function declared later in the block",
+			source: `{ func f() { return g(); } func g() { return 1; } print f(); }`,
+			want:   "1\n",
+		},
+		{
+			name:   "global until the local is declared",
+			source: `var g = "global"; { func f() { return g; } print f(); var g = "local"; print f(); }`,
+			want:   "global\nlocal\n",
+		},
+		{
+			name:   "redeclaration reuses the binding",
+			source: `{ var a = 1; func f() { return a; } var a = 2; print f(); }`,
+			want:   "2\n",
+		},
+		{
+			name:   "initializer sees the outer variable",
+			source: `{ var b = "outer"; { var b = b + "!"; print b; } print b; }`,
+			want:   "outer!\nouter\n",
+		},
+		{
+			name:   "duplicate parameters",
+			source: `func f(x, x) { return x; } print f(1, 2);`,
+			want:   "2\n",
+		},
+		{
+			name:   "assign before the local is declared",
+			source: `var n = 0; { func inc() { n = n + 1; } inc(); var n = 10; inc(); print n; } print n;`,
+			want:   "11\n1\n",
+		},
+		{
+			name:   "closures keep their own slots",
+			source: `func counter() { var c = 0; func inc() { c = c + 1; return c; } return inc; } var a = counter(); var b = counter(); a(); print a(); print b();`,
+			want:   "2\n1\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout, stderr := runSource(t, test.source)
+			if stderr != "" {
+				t.Fatalf("unexpected error:\n%s", stderr)
+			}
+			if stdout != test.want {
+				t.Errorf("got %q, want %q", stdout, test.want)
+			}
+		})
+	}
+}
+
+func TestResolverUndefinedLocal(t *testing.T) {
+	_, stderr := runSource(t, `{ func f() { return later; } print f(); var later = 1; }`)
+	if want := "Undefined variable 'later'.\n[line 1] in f()\n[line 1] in script\n"; stderr != want {
+		t.Errorf("got %q, want %q", stderr, want)
+	}
+}
+
+const (
+	fibSource = `
+func fib(n) {
+  if (n < 2) return n;
+  return fib(n - 2) + fib(n - 1);
+}
+print fib(20);
+`
+	loopSource = `
+var total = 0;
+for (var k = 0; k < 100000; k = k + 1) {
+  var doubled = k * 2;
+  total = total + doubled;
+}
+print total;
+`
+)
+
+func benchmarkSource(b *testing.B, source string) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		lox := NewLox(Config{Stdout: io.Discard})
+		lox.Run(source)
+		if lox.HasError() || lox.HasRuntimeError() {
+			b.Fatal("benchmark program failed")
+		}
+	}
+}
+
+func BenchmarkFib(b *testing.B) {
+	benchmarkSource(b, fibSource)
+}
+
+func BenchmarkLoop(b *testing.B) {
+	benchmarkSource(b, loopSource)
+}
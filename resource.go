@@ -0,0 +1,43 @@
+package main
+
+import "sync"
+
+type resource interface {
+	close()
+}
+
+type resourceRegistry struct {
+	mu        sync.Mutex
+	resources map[resource]struct{}
+}
+
+func newResourceRegistry() *resourceRegistry {
+	return &resourceRegistry{
+		resources: map[resource]struct{}{},
+	}
+}
+
+func (r *resourceRegistry) add(res resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resources[res] = struct{}{}
+}
+
+func (r *resourceRegistry) remove(res resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.resources, res)
+}
+
+func (r *resourceRegistry) closeAll() {
+	r.mu.Lock()
+	resources := r.resources
+	r.resources = map[resource]struct{}{}
+	r.mu.Unlock()
+
+	for res := range resources {
+		res.close()
+	}
+}
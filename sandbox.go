@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+type Capabilities struct {
+	ReadRoots  []string
+	WriteRoots []string
+	Env        bool
+	Clock      bool
+	Random     bool
+	Exit       bool
+}
+
+func FullCapabilities() Capabilities {
+	root := string(filepath.Separator)
+	return Capabilities{
+		ReadRoots:  []string{root},
+		WriteRoots: []string{root},
+		Env:        true,
+		Clock:      true,
+		Random:     true,
+		Exit:       true,
+	}
+}
+
+func (c Capabilities) CanRead(path string) bool {
+	return withinRoots(path, c.ReadRoots)
+}
+
+func (c Capabilities) CanWrite(path string) bool {
+	return withinRoots(path, c.WriteRoots)
+}
+
+func withinRoots(path string, roots []string) bool {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+
+	for _, root := range roots {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(resolvedRoot, resolved)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolvePath makes path absolute and resolves symlinks in its longest
+// existing prefix, so a link inside a root cannot point outside of it.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	rest := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return filepath.Join(abs, rest), nil
+		}
+		rest = filepath.Join(filepath.Base(abs), rest)
+		abs = parent
+	}
+}
+
+func (i *Interpreter) requireCapability(allowed bool, capability string) {
+	if !allowed {
+		msg := fmt.Sprintf("Permission denied: '%s' capability is not granted.", capability)
+		panic(i.error(i.callSite(), msg))
+	}
+}
+
+func (i *Interpreter) requireRead(path string) {
+	if !i.capabilities.CanRead(path) {
+		msg := fmt.Sprintf("Permission denied: cannot read '%s'.", path)
+		panic(i.error(i.callSite(), msg))
+	}
+}
+
+func (i *Interpreter) requireWrite(path string) {
+	if !i.capabilities.CanWrite(path) {
+		msg := fmt.Sprintf("Permission denied: cannot write '%s'.", path)
+		panic(i.error(i.callSite(), msg))
+	}
+}
@@ -1,20 +1,24 @@
+// Code generated by "genast -helpers=walk,pos,clone,transform"; DO NOT EDIT.
 
 package main
 
-
 type StmtVisitorVoid interface {
-    VisitExprStmt(expr *ExprStmt) 
-	VisitFunctionStmt(expr *FunctionStmt) 
-	VisitIfStmt(expr *IfStmt) 
-	VisitWhileStmt(expr *WhileStmt) 
-	VisitVarDeclStmt(expr *VarDeclStmt) 
-	VisitBlockStmt(expr *BlockStmt) 
-	VisitReturnStmt(expr *ReturnStmt) 
-	VisitPrintStmt(expr *PrintStmt) 
+	VisitExprStmt(expr *ExprStmt)
+	VisitFunctionStmt(expr *FunctionStmt)
+	VisitIfStmt(expr *IfStmt)
+	VisitWhileStmt(expr *WhileStmt)
+	VisitVarDeclStmt(expr *VarDeclStmt)
+	VisitBlockStmt(expr *BlockStmt)
+	VisitReturnStmt(expr *ReturnStmt)
+	VisitPrintStmt(expr *PrintStmt)
+	VisitSpawnStmt(expr *SpawnStmt)
+	VisitSelectStmt(expr *SelectStmt)
+	VisitYieldStmt(expr *YieldStmt)
+	VisitForInStmt(expr *ForInStmt)
 }
 
 type StmtVisitor[R any] interface {
-    VisitExprStmt(expr *ExprStmt) R
+	VisitExprStmt(expr *ExprStmt) R
 	VisitFunctionStmt(expr *FunctionStmt) R
 	VisitIfStmt(expr *IfStmt) R
 	VisitWhileStmt(expr *WhileStmt) R
@@ -22,155 +26,281 @@ type StmtVisitor[R any] interface {
 	VisitBlockStmt(expr *BlockStmt) R
 	VisitReturnStmt(expr *ReturnStmt) R
 	VisitPrintStmt(expr *PrintStmt) R
+	VisitSpawnStmt(expr *SpawnStmt) R
+	VisitSelectStmt(expr *SelectStmt) R
+	VisitYieldStmt(expr *YieldStmt) R
+	VisitForInStmt(expr *ForInStmt) R
 }
 
 type Stmt interface {
-    AcceptString(visitor StmtVisitor[string]) string
+	AcceptString(visitor StmtVisitor[string]) string
 	AcceptInterface(visitor StmtVisitor[interface{}]) interface{}
-	Accept(visitor StmtVisitorVoid) 
+	AcceptValue(visitor StmtVisitor[Value]) Value
+	Accept(visitor StmtVisitorVoid)
+	Children() []Node
+	Line() int
+	Clone() Stmt
+	TransformChildren(fn func(Node) Node)
 }
 
 type ExprStmt struct {
-    Expression Expr
+	Expression Expr
 }
 
 func (e *ExprStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitExprStmt(e)
+	return visitor.VisitExprStmt(e)
 }
 
 func (e *ExprStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitExprStmt(e)
+	return visitor.VisitExprStmt(e)
 }
 
-func (e *ExprStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitExprStmt(e)
+func (e *ExprStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitExprStmt(e)
 }
 
+func (e *ExprStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitExprStmt(e)
+}
 
 type FunctionStmt struct {
-    Name Token
-	Params []Token
-	Body []Stmt
+	Name      Token
+	Params    []Token
+	Body      []Stmt
+	Async     bool
+	Generator bool
 }
 
 func (e *FunctionStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitFunctionStmt(e)
+	return visitor.VisitFunctionStmt(e)
 }
 
 func (e *FunctionStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitFunctionStmt(e)
+	return visitor.VisitFunctionStmt(e)
 }
 
-func (e *FunctionStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitFunctionStmt(e)
+func (e *FunctionStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitFunctionStmt(e)
 }
 
+func (e *FunctionStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitFunctionStmt(e)
+}
 
 type IfStmt struct {
-    Condition Expr
-	Then Stmt
-	Else Stmt
+	Condition Expr
+	Then      Stmt
+	Else      Stmt
 }
 
 func (e *IfStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitIfStmt(e)
+	return visitor.VisitIfStmt(e)
 }
 
 func (e *IfStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitIfStmt(e)
+	return visitor.VisitIfStmt(e)
 }
 
-func (e *IfStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitIfStmt(e)
+func (e *IfStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitIfStmt(e)
 }
 
+func (e *IfStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitIfStmt(e)
+}
 
 type WhileStmt struct {
-    Condition Expr
+	Condition Expr
 	Statement Stmt
 }
 
 func (e *WhileStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitWhileStmt(e)
+	return visitor.VisitWhileStmt(e)
 }
 
 func (e *WhileStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitWhileStmt(e)
+	return visitor.VisitWhileStmt(e)
 }
 
-func (e *WhileStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitWhileStmt(e)
+func (e *WhileStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitWhileStmt(e)
 }
 
+func (e *WhileStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitWhileStmt(e)
+}
 
 type VarDeclStmt struct {
-    Name Token
+	Name        Token
 	Initializer Expr
 }
 
 func (e *VarDeclStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitVarDeclStmt(e)
+	return visitor.VisitVarDeclStmt(e)
 }
 
 func (e *VarDeclStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitVarDeclStmt(e)
+	return visitor.VisitVarDeclStmt(e)
 }
 
-func (e *VarDeclStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitVarDeclStmt(e)
+func (e *VarDeclStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitVarDeclStmt(e)
 }
 
+func (e *VarDeclStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitVarDeclStmt(e)
+}
 
 type BlockStmt struct {
-    Statements []Stmt
+	Statements []Stmt
+	Brace      Token
 }
 
 func (e *BlockStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitBlockStmt(e)
+	return visitor.VisitBlockStmt(e)
 }
 
 func (e *BlockStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitBlockStmt(e)
+	return visitor.VisitBlockStmt(e)
 }
 
-func (e *BlockStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitBlockStmt(e)
+func (e *BlockStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitBlockStmt(e)
 }
 
+func (e *BlockStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitBlockStmt(e)
+}
 
 type ReturnStmt struct {
-    Keyword Token
-	Value Expr
+	Keyword Token
+	Value   Expr
 }
 
 func (e *ReturnStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitReturnStmt(e)
+	return visitor.VisitReturnStmt(e)
 }
 
 func (e *ReturnStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitReturnStmt(e)
+	return visitor.VisitReturnStmt(e)
 }
 
-func (e *ReturnStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitReturnStmt(e)
+func (e *ReturnStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitReturnStmt(e)
 }
 
+func (e *ReturnStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitReturnStmt(e)
+}
 
 type PrintStmt struct {
-    Expression Expr
+	Expression Expr
 }
 
 func (e *PrintStmt) AcceptString(visitor StmtVisitor[string]) string {
-    return visitor.VisitPrintStmt(e)
+	return visitor.VisitPrintStmt(e)
 }
 
 func (e *PrintStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
-    return visitor.VisitPrintStmt(e)
+	return visitor.VisitPrintStmt(e)
+}
+
+func (e *PrintStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitPrintStmt(e)
 }
 
-func (e *PrintStmt) Accept(visitor StmtVisitorVoid)  {
-    visitor.VisitPrintStmt(e)
+func (e *PrintStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitPrintStmt(e)
 }
 
+type SpawnStmt struct {
+	Keyword Token
+	Call    *Call
+}
 
+func (e *SpawnStmt) AcceptString(visitor StmtVisitor[string]) string {
+	return visitor.VisitSpawnStmt(e)
+}
 
+func (e *SpawnStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
+	return visitor.VisitSpawnStmt(e)
+}
+
+func (e *SpawnStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitSpawnStmt(e)
+}
+
+func (e *SpawnStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitSpawnStmt(e)
+}
+
+type SelectStmt struct {
+	Keyword Token
+	Cases   []*SelectCase
+	Default *BlockStmt
+}
+
+func (e *SelectStmt) AcceptString(visitor StmtVisitor[string]) string {
+	return visitor.VisitSelectStmt(e)
+}
+
+func (e *SelectStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
+	return visitor.VisitSelectStmt(e)
+}
+
+func (e *SelectStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitSelectStmt(e)
+}
+
+func (e *SelectStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitSelectStmt(e)
+}
+
+type YieldStmt struct {
+	Keyword Token
+	Value   Expr
+}
+
+func (e *YieldStmt) AcceptString(visitor StmtVisitor[string]) string {
+	return visitor.VisitYieldStmt(e)
+}
+
+func (e *YieldStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
+	return visitor.VisitYieldStmt(e)
+}
+
+func (e *YieldStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitYieldStmt(e)
+}
+
+func (e *YieldStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitYieldStmt(e)
+}
+
+type ForInStmt struct {
+	Name     Token
+	Iterable Expr
+	Body     Stmt
+}
+
+func (e *ForInStmt) AcceptString(visitor StmtVisitor[string]) string {
+	return visitor.VisitForInStmt(e)
+}
+
+func (e *ForInStmt) AcceptInterface(visitor StmtVisitor[interface{}]) interface{} {
+	return visitor.VisitForInStmt(e)
+}
+
+func (e *ForInStmt) AcceptValue(visitor StmtVisitor[Value]) Value {
+	return visitor.VisitForInStmt(e)
+}
+
+func (e *ForInStmt) Accept(visitor StmtVisitorVoid) {
+	visitor.VisitForInStmt(e)
+}
+
+type SelectCase struct {
+	Name      Token
+	Operation *Call
+	Body      *BlockStmt
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func Stringify(val Value) string {
+	var builder strings.Builder
+	writeValue(&builder, val, map[interface{}]bool{})
+
+	return builder.String()
+}
+
+func writeValue(builder *strings.Builder, val Value, seen map[interface{}]bool) {
+	switch val.kind {
+	case NilKind:
+		builder.WriteString("nil")
+	case BoolKind:
+		builder.WriteString(strconv.FormatBool(val.num != 0))
+	case NumberKind:
+		builder.WriteString(formatNumber(val.num))
+	case StringKind:
+		builder.WriteString(val.ref.(string))
+	default:
+		writeObject(builder, val.ref, seen)
+	}
+}
+
+func writeObject(builder *strings.Builder, obj interface{}, seen map[interface{}]bool) {
+	switch v := obj.(type) {
+	case *Function:
+		fmt.Fprintf(builder, "<fn %s>", string(v.Definition.Name.Lexeme()))
+	case *NativeFunction, ClockFunction:
+		builder.WriteString("<native fn>")
+	case *List:
+		if seen[v] {
+			builder.WriteString("[...]")
+			return
+		}
+		seen[v] = true
+		builder.WriteString("[")
+		for k, element := range v.Elements() {
+			if k > 0 {
+				builder.WriteString(", ")
+			}
+			writeValue(builder, element, seen)
+		}
+		builder.WriteString("]")
+		delete(seen, v)
+	case *Map:
+		if seen[v] {
+			builder.WriteString("{...}")
+			return
+		}
+		seen[v] = true
+		builder.WriteString("{")
+		for k, key := range v.Keys() {
+			if k > 0 {
+				builder.WriteString(", ")
+			}
+			element, _ := v.Lookup(key)
+			builder.WriteString(key)
+			builder.WriteString(": ")
+			writeValue(builder, element, seen)
+		}
+		builder.WriteString("}")
+		delete(seen, v)
+	case fmt.Stringer:
+		builder.WriteString(v.String())
+	default:
+		fmt.Fprintf(builder, "%v", v)
+	}
+}
+
+func formatNumber(num float64) string {
+	switch {
+	case math.IsNaN(num):
+		return "nan"
+	case math.IsInf(num, 1):
+		return "inf"
+	case math.IsInf(num, -1):
+		return "-inf"
+	case num == math.Trunc(num) && math.Abs(num) < 1e21:
+		return strconv.FormatFloat(num, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(num, 'g', -1, 64)
+	}
+}
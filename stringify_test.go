@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/roycefanproxy/yaglox/constant"
+)
+
+func TestStringify(t *testing.T) {
+	nested := NewList([]Value{NewNumber(1), NewObject(NewList([]Value{NewString("a"), Nil}))})
+
+	cyclicList := NewList([]Value{NewNumber(1)})
+	cyclicList.elements = append(cyclicList.elements, NewObject(cyclicList))
+
+	m := NewMap()
+	m.Set("n", NewNumber(2.5))
+	m.Set("list", NewObject(NewList([]Value{NewBool(true)})))
+
+	cyclicMap := NewMap()
+	cyclicMap.Set("self", NewObject(cyclicMap))
+	cyclicMap.Set("list", NewObject(NewList([]Value{NewObject(cyclicMap)})))
+
+	// The same list twice is not a cycle.
+	shared := NewList([]Value{NewNumber(0)})
+	twice := NewList([]Value{NewObject(shared), NewObject(shared)})
+
+	tenth, fifth := 0.1, 0.2
+
+	function := &Function{Definition: &FunctionStmt{
+		Name: NewToken(constant.Identifier, []rune("x"), nil, 1),
+	}}
+
+	tests := []struct {
+		name  string
+		value Value
+		want  string
+	}{
+		{"nil", Nil, "nil"},
+		{"true", NewBool(true), "true"},
+		{"false", NewBool(false), "false"},
+		{"zero", NewNumber(0), "0"},
+		{"negative zero", NewNumber(math.Copysign(0, -1)), "-0"},
+		{"integer", NewNumber(42), "42"},
+		{"negative integer", NewNumber(-7), "-7"},
+		{"million", NewNumber(1e6), "1000000"},
+		{"largest plain integer", NewNumber(1e20), "100000000000000000000"},
+		{"1e21", NewNumber(1e21), "1e+21"},
+		{"above 1e21", NewNumber(1.5e300), "1.5e+300"},
+		{"fraction", NewNumber(tenth + fifth), "0.30000000000000004"},
+		{"small", NewNumber(1e-7), "1e-07"},
+		{"nan", NewNumber(math.NaN()), "nan"},
+		{"inf", NewNumber(math.Inf(1)), "inf"},
+		{"-inf", NewNumber(math.Inf(-1)), "-inf"},
+		{"string", NewString("hi there"), "hi there"},
+		{"function", NewObject(function), "<fn x>"},
+		{"native", NewObject(NewNativeFunction("len", 0, nil)), "<native fn>"},
+		{"clock", NewObject(ClockFunction{}), "<native fn>"},
+		{"empty list", NewObject(NewList(nil)), "[]"},
+		{"nested list", NewObject(nested), "[1, [a, nil]]"},
+		{"cyclic list", NewObject(cyclicList), "[1, [...]]"},
+		{"shared list", NewObject(twice), "[[0], [0]]"},
+		{"empty map", NewObject(NewMap()), "{}"},
+		{"map", NewObject(m), "{n: 2.5, list: [true]}"},
+		{"cyclic map", NewObject(cyclicMap), "{self: {...}, list: [{...}]}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Stringify(test.value); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPrintUsesStringify(t *testing.T) {
+	stdout, stderr := runSource(t, `
+func x() {}
+print nil; print 1000 * 1000; print 10 / 4; print x; print clock; print list(1, "a", list()); print str(-0);
+`)
+	if stderr != "" {
+		t.Fatalf("unexpected error:\n%s", stderr)
+	}
+	if want := "nil\n1000000\n2.5\n<fn x>\n<native fn>\n[1, a, []]\n-0\n"; stdout != want {
+		t.Errorf("got %q, want %q", stdout, want)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxStringLength caps the bytes repeat, join and replace may build, well
+// before computing the length could overflow an int. The length is checked
+// and charged before the string is built.
+const maxStringLength = 1 << 30
+
+func (i *Interpreter) stringMethod(str string, name Token) Value {
+	switch string(name.Lexeme()) {
+	case "len":
+		return method("len", 0, func(i *Interpreter, args []Value) Value {
+			return NewNumber(float64(utf8.RuneCountInString(str)))
+		})
+	case "upper":
+		return stringTransform("upper", str, strings.ToUpper)
+	case "lower":
+		return stringTransform("lower", str, strings.ToLower)
+	case "trim":
+		return stringTransform("trim", str, strings.TrimSpace)
+	case "split":
+		return method("split", 1, func(i *Interpreter, args []Value) Value {
+			parts := strings.Split(str, i.stringArg(args, 0))
+			return NewObject(i.newList(i.callSite(), toValues(parts)))
+		})
+	case "join":
+		return method("join", 1, func(i *Interpreter, args []Value) Value {
+			list := i.listArg(args, 0)
+			parts := []string{}
+			length := 0
+			for _, element := range list.Elements() {
+				part, ok := element.AsString()
+				if !ok {
+					panic(i.nativeError("Can only join lists of strings."))
+				}
+				size := len(part)
+				if len(parts) > 0 {
+					size += len(str)
+				}
+				if size > maxStringLength-length {
+					panic(i.nativeError("Joined string is too long."))
+				}
+				length += size
+				parts = append(parts, part)
+			}
+			i.allocateString(i.callSite(), length)
+			return NewString(strings.Join(parts, str))
+		})
+	case "replace":
+		return method("replace", 2, func(i *Interpreter, args []Value) Value {
+			from, to := i.stringArg(args, 0), i.stringArg(args, 1)
+			count := strings.Count(str, from)
+			if growth := len(to) - len(from); growth > 0 && count > (maxStringLength-len(str))/growth {
+				panic(i.nativeError("Replaced string is too long."))
+			}
+			i.allocateString(i.callSite(), len(str)+count*(len(to)-len(from)))
+			return NewString(strings.ReplaceAll(str, from, to))
+		})
+	case "contains":
+		return stringPredicate("contains", str, strings.Contains)
+	case "startsWith":
+		return stringPredicate("startsWith", str, strings.HasPrefix)
+	case "endsWith":
+		return stringPredicate("endsWith", str, strings.HasSuffix)
+	case "indexOf":
+		return method("indexOf", 1, func(i *Interpreter, args []Value) Value {
+			idx := strings.Index(str, i.stringArg(args, 0))
+			if idx < 0 {
+				return NewNumber(-1)
+			}
+			return NewNumber(float64(utf8.RuneCountInString(str[:idx])))
+		})
+	case "substring":
+		return method("substring", 2, func(i *Interpreter, args []Value) Value {
+			runes := []rune(str)
+			start := i.indexArg(args, 0, len(runes))
+			end := i.indexArg(args, 1, len(runes))
+			if start > end {
+				panic(i.nativeError("Substring start must not be after its end."))
+			}
+			i.allocateString(i.callSite(), len(string(runes[start:end])))
+			return NewString(string(runes[start:end]))
+		})
+	case "repeat":
+		return method("repeat", 1, func(i *Interpreter, args []Value) Value {
+			count := i.countArg(args, 0)
+			if count > 0 && len(str) > maxStringLength/count {
+				panic(i.nativeError("Repeated string is too long."))
+			}
+			i.allocateString(i.callSite(), len(str)*count)
+			return NewString(strings.Repeat(str, count))
+		})
+	case "chars":
+		return method("chars", 0, func(i *Interpreter, args []Value) Value {
+			elements := []Value{}
+			for _, char := range str {
+				elements = append(elements, NewString(string(char)))
+			}
+			return NewObject(i.newList(i.callSite(), elements))
+		})
+	case "codepoint":
+		return method("codepoint", 1, func(i *Interpreter, args []Value) Value {
+			runes := []rune(str)
+			return NewNumber(float64(runes[i.indexArg(args, 0, len(runes)-1)]))
+		})
+	}
+
+	panic(i.undefinedProperty(name))
+}
+
+func stringTransform(name, str string, fn func(string) string) Value {
+	return method(name, 0, func(i *Interpreter, args []Value) Value {
+		result := fn(str)
+		i.allocateString(i.callSite(), len(result))
+		return NewString(result)
+	})
+}
+
+func stringPredicate(name, str string, fn func(string, string) bool) Value {
+	return method(name, 1, func(i *Interpreter, args []Value) Value {
+		return NewBool(fn(str, i.stringArg(args, 0)))
+	})
+}
+
+func nativeFromCodepoint(i *Interpreter, args []Value) Value {
+	code := i.countArg(args, 0)
+	if code > utf8.MaxRune {
+		panic(i.nativeError("Argument 1 must be a valid code point."))
+	}
+
+	return NewString(string(rune(code)))
+}
+
+type stringIterator struct {
+	runes []rune
+	index int
+}
+
+func (it *stringIterator) Next(i *Interpreter) (Value, bool) {
+	if it.index >= len(it.runes) {
+		return Nil, false
+	}
+	char := string(it.runes[it.index])
+	it.index++
+
+	return NewString(char), true
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestRepeat(t *testing.T) {
+	tests := []scriptCase{
+		{`print "ab".repeat(3);`, "ababab\n", ""},
+		{`print "ab".repeat(0);`, "\n", ""},
+		{`print "".repeat(4611686018427387904);`, "\n", ""},
+		{`print "ab".repeat(4611686018427387904);`, "", "Repeated string is too long."},
+		{`print "ab".repeat(1073741824);`, "", "Repeated string is too long."},
+		{`print "ab".repeat(-1);`, "", "Argument 1 must be a non-negative integer."},
+	}
+
+	runScripts(t, Config{Capabilities: FullCapabilities()}, tests)
+}
+
+func TestJoinAndReplaceChargeFirst(t *testing.T) {
+	const big = `var big = "x".repeat(1000000);`
+	tests := []scriptCase{
+		{`print ", ".join(list("a", "b", "c"));`, "a, b, c\n", ""},
+		{`print "".join(list());`, "\n", ""},
+		{`print "banana".replace("an", "AN");`, "bANANa\n", ""},
+		{`print "ab".replace("", "-");`, "-a-b-\n", ""},
+		{`print "aaa".replace("aa", "");`, "a\n", ""},
+		{big + `big.replace("", big);`, "", "Replaced string is too long."},
+		{big + `var l = list(); for (var k = 0; k < 2000; k = k + 1) l.push(big); "".join(l);`, "", "Joined string is too long."},
+		{big + `var l = list(); for (var k = 0; k < 20; k = k + 1) l.push(big); "".join(l);`, "", "Memory limit of 10000000 bytes exceeded."},
+		{big + `big.replace("x", "0123456789");`, "", "Memory limit of 10000000 bytes exceeded."},
+	}
+
+	runScripts(t, Config{Capabilities: FullCapabilities(), MaxAllocBytes: 10000000}, tests)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type syncReader struct {
+	mu     sync.Mutex
+	reader *bufio.Reader
+}
+
+func newSyncReader(in io.Reader) *syncReader {
+	return &syncReader{
+		reader: bufio.NewReader(in),
+	}
+}
+
+type exitSignal struct {
+	code int
+}
+
+// exitStatus is shared by every task of an interpreter. Once exit has been
+// called, each task unwinds at its next statement or blocked channel
+// operation; stopping lets execute check for that without taking mu.
+type exitStatus struct {
+	mu        sync.Mutex
+	code      int
+	requested bool
+	stopping  int32
+}
+
+func (s *exitStatus) set(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.requested {
+		s.code, s.requested = code, true
+		atomic.StoreInt32(&s.stopping, 1)
+	}
+}
+
+func (s *exitStatus) stopped() bool {
+	return atomic.LoadInt32(&s.stopping) != 0
+}
+
+func (s *exitStatus) signal() *exitSignal {
+	code, _ := s.get()
+	return &exitSignal{code: code}
+}
+
+func (s *exitStatus) get() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.code, s.requested
+}
+
+func defineSystemNatives(env *GlobalEnvironment, args []string) {
+	env.Define("args", NewObject(NewList(toValues(args))))
+	env.Define("input", method("input", 1, nativeInput))
+	env.Define("readLine", method("readLine", 0, nativeReadLine))
+	env.Define("readAll", method("readAll", 0, nativeReadAll))
+	env.Define("getenv", method("getenv", 1, nativeGetenv))
+	env.Define("environ", method("environ", 0, nativeEnviron))
+	env.Define("exit", method("exit", 1, nativeExit))
+}
+
+func nativeInput(i *Interpreter, args []Value) Value {
+	fmt.Fprint(i.stdout, i.stringify(args[0]))
+	return i.readLine()
+}
+
+func nativeReadLine(i *Interpreter, args []Value) Value {
+	return i.readLine()
+}
+
+func (i *Interpreter) readLine() Value {
+	i.stdin.mu.Lock()
+	line, err := i.stdin.reader.ReadString('\n')
+	i.stdin.mu.Unlock()
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		panic(i.nativeError(fmt.Sprintf("Can't read input: %v.", err)))
+	}
+	if line == "" && err != nil {
+		return Nil
+	}
+	i.allocateString(i.callSite(), len(line))
+
+	return NewString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+}
+
+func nativeReadAll(i *Interpreter, args []Value) Value {
+	i.stdin.mu.Lock()
+	bin, err := io.ReadAll(i.stdin.reader)
+	i.stdin.mu.Unlock()
+
+	if err != nil {
+		panic(i.nativeError(fmt.Sprintf("Can't read input: %v.", err)))
+	}
+	if len(bin) == 0 {
+		return Nil
+	}
+	i.allocateString(i.callSite(), len(bin))
+
+	return NewString(string(bin))
+}
+
+func nativeGetenv(i *Interpreter, args []Value) Value {
+	i.requireCapability(i.capabilities.Env, "env")
+
+	val, ok := os.LookupEnv(i.stringArg(args, 0))
+	if !ok {
+		return Nil
+	}
+
+	return NewString(val)
+}
+
+func nativeEnviron(i *Interpreter, args []Value) Value {
+	i.requireCapability(i.capabilities.Env, "env")
+
+	environ := os.Environ()
+	sort.Strings(environ)
+
+	m := i.newMap(i.callSite())
+	for _, entry := range environ {
+		key, val, _ := strings.Cut(entry, "=")
+		i.allocate(i.callSite(), mapEntrySize+len(entry))
+		m.Set(key, NewString(val))
+	}
+
+	return NewObject(m)
+}
+
+func nativeExit(i *Interpreter, args []Value) Value {
+	i.requireCapability(i.capabilities.Exit, "exit")
+
+	code := i.numberArg(args, 0)
+	if code != float64(int(code)) || code < 0 || code > 255 {
+		panic(i.nativeError("Exit code must be an integer between 0 and 255."))
+	}
+
+	panic(&exitSignal{code: int(code)})
+}
+
+// requestExit records the exit code and stops every task: the others see
+// it at their next statement, and blocked channel operations are cancelled.
+func (i *Interpreter) requestExit(code int) {
+	i.exit.set(code)
+	i.channels.cancel(errExiting)
+}
+
+func (i *Interpreter) ExitCode() (int, bool) {
+	return i.exit.get()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExitStopsEveryTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		stdout string
+		code   int
+	}{
+		{"main", `exit(1); print "no";`, "", 1},
+		{"spawned while the script runs", `spawn exit(4); while (true) {}`, "", 4},
+		{"spawned while the script is blocked", `var ch = channel(0); spawn exit(3); receive(ch); print "no";`, "", 3},
+		{"main while a spawned call is blocked", `func w(c) { receive(c); print "no"; } var c = channel(0); spawn w(c); exit(5);`, "", 5},
+		{"spawned while another spawned call runs", `func spin() { while (true) {} } spawn spin(); spawn exit(8); receive(channel(0));`, "", 8},
+		{"generator", `func g() { yield 1; exit(6); yield 2; } for (var x in g()) print x; print "no";`, "1\n", 6},
+		{"async", `async func a() { exit(7); } await a(); print "no";`, "", 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			lox := NewLox(Config{Capabilities: FullCapabilities(), Stdout: &stdout, Stderr: &stderr})
+			lox.Run(test.source)
+			lox.Close()
+
+			if stdout.String() != test.stdout || stderr.Len() > 0 {
+				t.Errorf("got %q and error %q, want %q", stdout.String(), stderr.String(), test.stdout)
+			}
+			if code, ok := lox.ExitCode(); !ok || code != test.code {
+				t.Errorf("got exit code %d (requested %v), want %d", code, ok, test.code)
+			}
+		})
+	}
+}
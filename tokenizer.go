@@ -12,22 +12,30 @@ var (
 
 func init() {
 	keywords = map[string]constant.TokenType{
-		"and":    constant.And,
-		"class":  constant.Class,
-		"else":   constant.Else,
-		"false":  constant.False,
-		"for":    constant.For,
-		"func":   constant.Func,
-		"if":     constant.If,
-		"nil":    constant.Nil,
-		"or":     constant.Or,
-		"print":  constant.Print,
-		"return": constant.Return,
-		"super":  constant.Super,
-		"this":   constant.This,
-		"true":   constant.True,
-		"var":    constant.Var,
-		"while":  constant.While,
+		"and":     constant.And,
+		"class":   constant.Class,
+		"else":    constant.Else,
+		"false":   constant.False,
+		"for":     constant.For,
+		"func":    constant.Func,
+		"if":      constant.If,
+		"nil":     constant.Nil,
+		"or":      constant.Or,
+		"print":   constant.Print,
+		"return":  constant.Return,
+		"super":   constant.Super,
+		"this":    constant.This,
+		"true":    constant.True,
+		"var":     constant.Var,
+		"while":   constant.While,
+		"spawn":   constant.Spawn,
+		"select":  constant.Select,
+		"case":    constant.Case,
+		"default": constant.Default,
+		"async":   constant.Async,
+		"await":   constant.Await,
+		"yield":   constant.Yield,
+		"in":      constant.In,
 	}
 }
 
@@ -36,16 +44,18 @@ type Tokenizer struct {
 	runes                []rune
 	tokens               []Token
 	start, current, line int
+	reporter             *ErrorReporter
 }
 
-func NewTokenizer(src string) *Tokenizer {
+func NewTokenizer(src string, reporter *ErrorReporter) *Tokenizer {
 	return &Tokenizer{
-		src:     src,
-		runes:   []rune(src),
-		tokens:  []Token{},
-		start:   0,
-		current: 0,
-		line:    1,
+		src:      src,
+		runes:    []rune(src),
+		tokens:   []Token{},
+		start:    0,
+		current:  0,
+		line:     1,
+		reporter: reporter,
 	}
 }
 
@@ -136,7 +146,7 @@ func (s *Tokenizer) parseOne() {
 		} else if s.isAlpha(char) {
 			s.identifier()
 		} else {
-			EmitErrorLog(s.line, "Unexpected character.")
+			s.reporter.EmitErrorLog(s.line, "Unexpected character.")
 		}
 	}
 }
@@ -193,7 +203,7 @@ func (s *Tokenizer) string() {
 	}
 
 	if s.isAtEnd() {
-		EmitErrorLog(s.line, "Unterminated string.")
+		s.reporter.EmitErrorLog(s.line, "Unterminated string.")
 		return
 	}
 
@@ -0,0 +1,109 @@
+package main
+
+type ValueKind uint8
+
+const (
+	NilKind ValueKind = iota
+	BoolKind
+	NumberKind
+	StringKind
+	ObjectKind
+)
+
+// Value is a Lox value. Booleans and numbers live in num so that arithmetic
+// never allocates; strings and objects (functions, lists, maps, ...) live in
+// ref.
+type Value struct {
+	kind ValueKind
+	num  float64
+	ref  interface{}
+}
+
+var Nil = Value{}
+
+func NewBool(b bool) Value {
+	if b {
+		return Value{kind: BoolKind, num: 1}
+	}
+	return Value{kind: BoolKind}
+}
+
+func NewNumber(num float64) Value {
+	return Value{kind: NumberKind, num: num}
+}
+
+func NewString(str string) Value {
+	return Value{kind: StringKind, ref: str}
+}
+
+func NewObject(obj interface{}) Value {
+	return Value{kind: ObjectKind, ref: obj}
+}
+
+// ValueOf converts a Go nil, bool, float64, string or object to a Value.
+func ValueOf(val interface{}) Value {
+	switch v := val.(type) {
+	case nil:
+		return Nil
+	case Value:
+		return v
+	case bool:
+		return NewBool(v)
+	case float64:
+		return NewNumber(v)
+	case string:
+		return NewString(v)
+	default:
+		return NewObject(v)
+	}
+}
+
+func (v Value) Kind() ValueKind {
+	return v.kind
+}
+
+func (v Value) IsNil() bool {
+	return v.kind == NilKind
+}
+
+func (v Value) AsBool() (bool, bool) {
+	return v.num != 0, v.kind == BoolKind
+}
+
+func (v Value) AsNumber() (float64, bool) {
+	return v.num, v.kind == NumberKind
+}
+
+func (v Value) AsString() (string, bool) {
+	if v.kind != StringKind {
+		return "", false
+	}
+	return v.ref.(string), true
+}
+
+// AsObject returns the object a Value refers to, or nil for nil, booleans,
+// numbers and strings.
+func (v Value) AsObject() interface{} {
+	if v.kind != ObjectKind {
+		return nil
+	}
+	return v.ref
+}
+
+// Interface is the inverse of ValueOf.
+func (v Value) Interface() interface{} {
+	switch v.kind {
+	case BoolKind:
+		return v.num != 0
+	case NumberKind:
+		return v.num
+	case StringKind, ObjectKind:
+		return v.ref
+	default:
+		return nil
+	}
+}
+
+func (v Value) String() string {
+	return Stringify(v)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+// arithmetic returns an interpreter with x defined and a resolved
+// expression that does number arithmetic, comparisons and equality on it.
+func arithmetic(tb testing.TB) (*Interpreter, Expr) {
+	tb.Helper()
+
+	lox := NewLox(Config{Stdout: io.Discard})
+	lox.Run("var x = 3;")
+	statements := lox.Parse("(x * 2 + 1) / 4 - x < 100 == !(x >= 7);")
+	if statements == nil {
+		tb.Fatal("can't parse the expression")
+	}
+	NewResolver(lox.interpreter.Globals).Resolve(statements)
+
+	return lox.interpreter, statements[0].(*ExprStmt).Expression
+}
+
+func TestArithmeticDoesNotAllocate(t *testing.T) {
+	interpreter, expr := arithmetic(t)
+	allocs := testing.AllocsPerRun(100, func() {
+		interpreter.evaluate(expr)
+	})
+	if allocs != 0 {
+		t.Errorf("evaluating numbers allocated %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkArithmetic(b *testing.B) {
+	interpreter, expr := arithmetic(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		interpreter.evaluate(expr)
+	}
+}
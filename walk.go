@@ -0,0 +1,66 @@
+package main
+
+// A Visitor's Visit method is called by Walk for every node. If it returns a
+// non-nil Visitor w, Walk visits the node's children with w and then calls
+// w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	for _, child := range node.Children() {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+func WalkProgram(v Visitor, statements []Stmt) {
+	for _, stmt := range statements {
+		Walk(v, stmt)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect calls f for node and, while f returns true, for each of its
+// children in source order, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+func InspectProgram(statements []Stmt, f func(Node) bool) {
+	WalkProgram(inspector(f), statements)
+}
+
+// Transform rewrites the tree rooted at node bottom-up: the children of a
+// node are transformed before fn is called on the node itself, and the node
+// is replaced by whatever fn returns. A nil result removes the node from the
+// statement or expression list it is in, or clears the field that held it,
+// so fn should only return nil where the tree may have nothing, such as for
+// a statement in a block. A result that can't take the node's place, such as
+// a statement in place of an expression, panics. The tree is changed in
+// place, so Clone it first to keep the original.
+func Transform(node Node, fn func(Node) Node) Node {
+	node.TransformChildren(func(child Node) Node {
+		return Transform(child, fn)
+	})
+
+	return fn(node)
+}
+
+func TransformProgram(statements []Stmt, fn func(Node) Node) []Stmt {
+	return transformStmts(statements, func(stmt Node) Node {
+		return Transform(stmt, fn)
+	})
+}
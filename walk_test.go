@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestTransformNil(t *testing.T) {
+	lox := NewLox(Config{Stderr: io.Discard})
+	statements := lox.Parse("f(1, nil, 2); var x = nil; print 3;")
+	if statements == nil {
+		t.Fatal("the source didn't parse")
+	}
+
+	statements = TransformProgram(statements, func(node Node) Node {
+		switch n := node.(type) {
+		case *Literal:
+			if n.Value == nil {
+				return nil
+			}
+		case *PrintStmt:
+			return nil
+		}
+		return node
+	})
+
+	if len(statements) != 2 {
+		t.Fatalf("got %d statements, want the print statement removed", len(statements))
+	}
+	if args := statements[0].(*ExprStmt).Expression.(*Call).Arguments; len(args) != 2 {
+		t.Errorf("got %d arguments, want nil removed from the list", len(args))
+	}
+	if init := statements[1].(*VarDeclStmt).Initializer; init != nil {
+		t.Errorf("got initializer %v, want it cleared", init)
+	}
+}
+
+func TestTransformNilClearsNodeFields(t *testing.T) {
+	lox := NewLox(Config{Stderr: io.Discard})
+	statements := lox.Parse("select { default { print 1; } }")
+	if statements == nil {
+		t.Fatal("the source didn't parse")
+	}
+
+	statements = TransformProgram(statements, func(node Node) Node {
+		if block, ok := node.(*BlockStmt); ok && block.Brace != nil {
+			return nil
+		}
+		return node
+	})
+
+	if stmt := statements[0].(*SelectStmt); stmt.Default != nil {
+		t.Errorf("got default %v, want it cleared", stmt.Default)
+	}
+}
+
+func TestTransformWrongType(t *testing.T) {
+	lox := NewLox(Config{Stderr: io.Discard})
+	statements := lox.Parse("spawn f(); print 1 + 2;")
+	if statements == nil {
+		t.Fatal("the source didn't parse")
+	}
+
+	tests := []struct {
+		name string
+		from Node
+		want string
+	}{
+		{"node field", statements[0].(*SpawnStmt).Call, "transform returned *main.PrintStmt where *Call is needed"},
+		{"family field", statements[1].(*PrintStmt).Expression, "transform returned *main.PrintStmt where Expr is needed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != test.want {
+					t.Errorf("got panic %v, want %q", r, test.want)
+				}
+			}()
+
+			TransformProgram(statements, func(node Node) Node {
+				if node == test.from {
+					return &PrintStmt{}
+				}
+				return node
+			})
+		})
+	}
+}